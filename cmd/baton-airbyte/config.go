@@ -1,18 +1,145 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	"github.com/conductorone/baton-airbyte/pkg/connector"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/spf13/viper"
 )
 
 var (
-	Hostname     = field.StringField("hostname", field.WithRequired(true), field.WithDescription("The Airbyte hostname used to connect to the Airbyte API"))
-	ClientId     = field.StringField("airbyte-client-id", field.WithRequired(true), field.WithDescription("The Airbyte client id used to connect to the Airbyte API."))
-	ClientSecret = field.StringField("airbyte-client-secret", field.WithRequired(true), field.WithDescription("The Airbyte client secret used to connect to the Airbyte API."))
+	Hostname        = field.StringField("hostname", field.WithDescription("The Airbyte hostname used to connect to the Airbyte API, as an absolute URL such as https://airbyte.example.com"))
+	ClientId        = field.StringField("airbyte-client-id", field.WithDescription("The Airbyte client id used to connect to the Airbyte API."))
+	ClientSecret    = field.StringField("airbyte-client-secret", field.WithDescription("The Airbyte client secret used to connect to the Airbyte API."))
+	DeploymentsFile = field.StringField(
+		"deployments-file",
+		field.WithDescription("Path to a JSON file listing the Airbyte deployments to sync, instead of a single hostname."),
+	)
+	AuthMethod = field.StringField(
+		"auth-method",
+		field.WithDescription("How requests to the Airbyte API are authenticated: client-credentials, bearer-token, keycloak-password or none. Inferred from the credentials given when unset."),
+		field.WithString(func(r *field.StringRuler) {
+			r.In([]string{
+				airbyte.AuthMethodClientCredentials,
+				airbyte.AuthMethodBearerToken,
+				airbyte.AuthMethodKeycloakPassword,
+				airbyte.AuthMethodNone,
+			})
+		}),
+	)
+	BearerToken = field.StringField(
+		"airbyte-bearer-token",
+		field.WithDescription("A pre-issued access token used to connect to the Airbyte API with the bearer-token auth method."),
+		field.WithIsSecret(true),
+	)
+	Username = field.StringField(
+		"airbyte-username",
+		field.WithDescription("The Airbyte user used to connect to the Airbyte API with the keycloak-password auth method."),
+	)
+	Password = field.StringField(
+		"airbyte-password",
+		field.WithDescription("The password of the Airbyte user used with the keycloak-password auth method."),
+		field.WithIsSecret(true),
+	)
+	KeycloakRealm = field.StringField(
+		"keycloak-realm",
+		field.WithDefaultValue(airbyte.DefaultKeycloakRealm),
+		field.WithDescription("The Keycloak realm the Airbyte user authenticates against with the keycloak-password auth method."),
+	)
+	KeycloakClientID = field.StringField(
+		"keycloak-client-id",
+		field.WithDefaultValue(airbyte.DefaultKeycloakClientID),
+		field.WithDescription("The Keycloak client used to authenticate the Airbyte user with the keycloak-password auth method."),
+	)
+	DeploymentProfile = field.StringField(
+		"deployment-profile",
+		field.WithDefaultValue(airbyte.DeploymentProfileSelfManaged),
+		field.WithDescription("The layout of the Airbyte APIs: self-managed, or cloud for Airbyte Cloud, which needs no hostname."),
+		field.WithString(func(r *field.StringRuler) {
+			r.In([]string{airbyte.DeploymentProfileSelfManaged, airbyte.DeploymentProfileCloud})
+		}),
+	)
+	PublicAPIURL = field.StringField(
+		"public-api-url",
+		field.WithDescription("Overrides the root of the Airbyte public API of the deployment profile, e.g. https://api.airbyte.com/v1."),
+	)
+	ConfigAPIURL = field.StringField(
+		"config-api-url",
+		field.WithDescription("Overrides the root of the Airbyte config API of the deployment profile, e.g. https://airbyte.example.com/api/v1."),
+	)
+	TokenURL = field.StringField(
+		"token-url",
+		field.WithDescription("Overrides the endpoint issuing access tokens for application credentials of the deployment profile."),
+	)
+	RateLimitMaxRetries = field.IntField(
+		"rate-limit-max-retries",
+		field.WithDefaultValue(5),
+		field.WithDescription("The maximum number of times a request rate limited by Airbyte is retried."),
+		field.WithInt(func(r *field.IntRuler) { r.Gte(0) }),
+	)
+	RateLimitMaxWaitSeconds = field.IntField(
+		"rate-limit-max-wait-seconds",
+		field.WithDefaultValue(120),
+		field.WithDescription("The maximum number of seconds a single request may wait for Airbyte rate limits to reset."),
+		field.WithInt(func(r *field.IntRuler) { r.Gte(0) }),
+	)
+	TopLevelUnattributedWorkspaces = field.BoolField(
+		"top-level-unattributed-workspaces",
+		field.WithDefaultValue(false),
+		field.WithDescription("List workspaces whose organization the connector can't access as top-level resources instead of under a synthetic unassigned organization."),
+	)
+	SkipTombstonedWorkspaces = field.BoolField(
+		"skip-tombstoned-workspaces",
+		field.WithDefaultValue(false),
+		field.WithDescription("Skip tombstoned workspaces instead of syncing them flagged in their profile."),
+	)
+	OrganizationInclude = field.StringSliceField(
+		"organization-include",
+		field.WithDescription("Sync only the organizations matching one of these IDs, name globs or /regular expressions/."),
+	)
+	OrganizationExclude = field.StringSliceField(
+		"organization-exclude",
+		field.WithDescription("Skip the organizations matching one of these IDs, name globs or /regular expressions/, along with their workspaces."),
+	)
+	WorkspaceInclude = field.StringSliceField(
+		"workspace-include",
+		field.WithDescription("Sync only the workspaces matching one of these IDs, name globs or /regular expressions/."),
+	)
+	WorkspaceExclude = field.StringSliceField(
+		"workspace-exclude",
+		field.WithDescription("Skip the workspaces matching one of these IDs, name globs or /regular expressions/."),
+	)
 	// ConfigurationFields defines the external configuration required for the
 	// connector to run. Note: these fields can be marked as optional or
 	// required.
-	ConfigurationFields = []field.SchemaField{Hostname, ClientId, ClientSecret}
+	ConfigurationFields = []field.SchemaField{
+		Hostname,
+		ClientId,
+		ClientSecret,
+		AuthMethod,
+		BearerToken,
+		Username,
+		Password,
+		KeycloakRealm,
+		KeycloakClientID,
+		DeploymentProfile,
+		PublicAPIURL,
+		ConfigAPIURL,
+		TokenURL,
+		DeploymentsFile,
+		RateLimitMaxRetries,
+		RateLimitMaxWaitSeconds,
+		TopLevelUnattributedWorkspaces,
+		SkipTombstonedWorkspaces,
+		OrganizationInclude,
+		OrganizationExclude,
+		WorkspaceInclude,
+		WorkspaceExclude,
+	}
 
 	// FieldRelationships defines relationships between the fields listed in
 	// ConfigurationFields that can be automatically validated. For example, a
@@ -20,6 +147,12 @@ var (
 	// marked as mutually exclusive from the username password pair.
 	FieldRelationships = []field.SchemaFieldRelationship{
 		field.FieldsRequiredTogether(ClientId, ClientSecret),
+		field.FieldsRequiredTogether(Username, Password),
+		field.FieldsMutuallyExclusive(ClientId, BearerToken, Username, DeploymentsFile),
+		field.FieldsMutuallyExclusive(Hostname, DeploymentsFile),
+		field.FieldsMutuallyExclusive(PublicAPIURL, DeploymentsFile),
+		field.FieldsMutuallyExclusive(ConfigAPIURL, DeploymentsFile),
+		field.FieldsMutuallyExclusive(TokenURL, DeploymentsFile),
 	}
 
 	cfg = field.Configuration{
@@ -33,5 +166,92 @@ var (
 // needs to perform extra validations that cannot be encoded with configuration
 // parameters.
 func ValidateConfig(v *viper.Viper) error {
+	_, err := loadDeploymentsConfig(v)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = loadFilters(v)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// loadDeploymentsConfig returns the deployments to sync, read from the deployments file if one is set, or else the
+// single deployment configured with the hostname, the deployment profile and the credentials of its auth method.
+func loadDeploymentsConfig(v *viper.Viper) ([]connector.Deployment, error) {
+	if path := v.GetString(DeploymentsFile.FieldName); path != "" {
+		return loadDeployments(path)
+	}
+
+	deployments := []connector.Deployment{
+		{
+			Hostname:         v.GetString(Hostname.FieldName),
+			Profile:          v.GetString(DeploymentProfile.FieldName),
+			PublicAPIURL:     v.GetString(PublicAPIURL.FieldName),
+			ConfigAPIURL:     v.GetString(ConfigAPIURL.FieldName),
+			TokenURL:         v.GetString(TokenURL.FieldName),
+			AuthMethod:       v.GetString(AuthMethod.FieldName),
+			ClientID:         v.GetString(ClientId.FieldName),
+			ClientSecret:     v.GetString(ClientSecret.FieldName),
+			BearerToken:      v.GetString(BearerToken.FieldName),
+			Username:         v.GetString(Username.FieldName),
+			Password:         v.GetString(Password.FieldName),
+			KeycloakRealm:    v.GetString(KeycloakRealm.FieldName),
+			KeycloakClientID: v.GetString(KeycloakClientID.FieldName),
+		},
+	}
+
+	err := connector.ValidateDeployments(deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+// loadFilters builds the organization and workspace filters from the configuration.
+func loadFilters(v *viper.Viper) (*connector.ResourceFilter, *connector.ResourceFilter, error) {
+	organizationFilter, err := connector.NewResourceFilter(
+		v.GetStringSlice(OrganizationInclude.FieldName),
+		v.GetStringSlice(OrganizationExclude.FieldName),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid organization filter: %w", err)
+	}
+
+	workspaceFilter, err := connector.NewResourceFilter(
+		v.GetStringSlice(WorkspaceInclude.FieldName),
+		v.GetStringSlice(WorkspaceExclude.FieldName),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid workspace filter: %w", err)
+	}
+
+	return organizationFilter, workspaceFilter, nil
+}
+
+// loadDeployments reads the deployments to sync from a JSON file, e.g.
+//
+//	[{"name": "eu", "hostname": "https://airbyte.eu.example.com", "client_id": "...", "client_secret": "..."}]
+func loadDeployments(path string) ([]connector.Deployment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployments file: %w", err)
+	}
+
+	var deployments []connector.Deployment
+	err = json.Unmarshal(data, &deployments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse deployments file %s: %w", path, err)
+	}
+
+	err = connector.ValidateDeployments(deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
@@ -14,7 +14,212 @@ func TestConfigs(t *testing.T) {
 	)
 
 	testCases := []test.TestCase{
-		// Add test cases here.
+		{
+			Configs: map[string]string{
+				"hostname":              "https://airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+			},
+			IsValid: true,
+			Message: "default rate limit settings",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":                    "https://airbyte.example.com",
+				"airbyte-client-id":           "client-id",
+				"airbyte-client-secret":       "client-secret",
+				"rate-limit-max-retries":      "0",
+				"rate-limit-max-wait-seconds": "30",
+			},
+			IsValid: true,
+			Message: "retries disabled",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":               "https://airbyte.example.com",
+				"airbyte-client-id":      "client-id",
+				"airbyte-client-secret":  "client-secret",
+				"rate-limit-max-retries": "-1",
+			},
+			IsValid: false,
+			Message: "negative retries",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":             "https://airbyte.example.com",
+				"auth-method":          "bearer-token",
+				"airbyte-bearer-token": "token",
+			},
+			IsValid: true,
+			Message: "bearer token",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":         "https://airbyte.example.com",
+				"auth-method":      "keycloak-password",
+				"airbyte-username": "jane@example.com",
+				"airbyte-password": "password",
+				"keycloak-realm":   "airbyte",
+			},
+			IsValid: true,
+			Message: "keycloak password",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":         "https://airbyte.example.com",
+				"auth-method":      "keycloak-password",
+				"airbyte-username": "jane@example.com",
+			},
+			IsValid: false,
+			Message: "keycloak username without password",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":             "https://airbyte.example.com",
+				"airbyte-bearer-token": "token",
+			},
+			IsValid: true,
+			Message: "bearer token without auth method",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":         "https://airbyte.example.com",
+				"airbyte-username": "jane@example.com",
+				"airbyte-password": "password",
+			},
+			IsValid: true,
+			Message: "keycloak password without auth method",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":              "https://airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+				"airbyte-bearer-token":  "token",
+			},
+			IsValid: false,
+			Message: "client credentials and bearer token",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":    "http://localhost:8000",
+				"auth-method": "none",
+			},
+			IsValid: true,
+			Message: "no authentication",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":    "https://airbyte.example.com",
+				"auth-method": "basic",
+			},
+			IsValid: false,
+			Message: "unsupported auth method",
+		},
+		{
+			Configs: map[string]string{
+				"deployment-profile":    "cloud",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+			},
+			IsValid: true,
+			Message: "airbyte cloud without hostname",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":              "https://airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+				"public-api-url":        "/public/v1",
+				"token-url":             "https://auth.example.com/token",
+			},
+			IsValid: true,
+			Message: "self-managed with endpoint overrides",
+		},
+		{
+			Configs: map[string]string{
+				"deployment-profile":    "serverless",
+				"hostname":              "https://airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+			},
+			IsValid: false,
+			Message: "unsupported deployment profile",
+		},
+		{
+			Configs: map[string]string{
+				"deployments-file": "testdata/deployments.json",
+				"config-api-url":   "https://airbyte.example.com/api/v1",
+			},
+			IsValid: false,
+			Message: "endpoint override with deployments file",
+		},
+		{
+			Configs: map[string]string{
+				"deployments-file": "testdata/deployments.json",
+			},
+			IsValid: true,
+			Message: "deployments file",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":              "https://airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+				"organization-include":  "/^prod-/",
+				"workspace-exclude":     "sandbox-*",
+			},
+			IsValid: true,
+			Message: "organization and workspace filters",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":              "https://airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+				"workspace-include":     "/[/",
+			},
+			IsValid: false,
+			Message: "invalid workspace filter regular expression",
+		},
+		{
+			Configs: map[string]string{
+				"deployments-file": "testdata/deployments_duplicate.json",
+			},
+			IsValid: false,
+			Message: "duplicate deployment names",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":              "https://airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+				"deployments-file":      "testdata/deployments.json",
+			},
+			IsValid: false,
+			Message: "hostname and deployments file",
+		},
+		{
+			Configs: map[string]string{
+				"hostname": "https://airbyte.example.com",
+			},
+			IsValid: false,
+			Message: "hostname without credentials",
+		},
+		{
+			Configs: map[string]string{
+				"hostname":              "airbyte.example.com",
+				"airbyte-client-id":     "client-id",
+				"airbyte-client-secret": "client-secret",
+			},
+			IsValid: false,
+			Message: "hostname without scheme",
+		},
+		{
+			Configs: map[string]string{},
+			IsValid: false,
+			Message: "no deployment",
+		},
 	}
 
 	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, testCases)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
 	"github.com/conductorone/baton-airbyte/pkg/connector"
 	"github.com/conductorone/baton-sdk/pkg/config"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
@@ -46,11 +48,31 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 		return nil, err
 	}
 
-	hostname := v.GetString("hostname")
-	clientId := v.GetString("airbyte-client-id")
-	clientSecret := v.GetString("airbyte-client-secret")
+	deployments, err := loadDeploymentsConfig(v)
+	if err != nil {
+		l.Error("error loading deployments", zap.Error(err))
+		return nil, err
+	}
+
+	organizationFilter, workspaceFilter, err := loadFilters(v)
+	if err != nil {
+		l.Error("error loading filters", zap.Error(err))
+		return nil, err
+	}
+
+	retryPolicy := airbyte.DefaultRetryPolicy
+	retryPolicy.MaxRetries = v.GetInt(RateLimitMaxRetries.FieldName)
+	retryPolicy.MaxWait = time.Duration(v.GetInt(RateLimitMaxWaitSeconds.FieldName)) * time.Second
 
-	cb, err := connector.New(ctx, hostname, clientId, clientSecret)
+	cb, err := connector.NewWithDeployments(
+		ctx,
+		deployments,
+		connector.WithClientOptions(airbyte.WithRetryPolicy(retryPolicy)),
+		connector.WithTopLevelUnattributedWorkspaces(v.GetBool(TopLevelUnattributedWorkspaces.FieldName)),
+		connector.WithSkipTombstonedWorkspaces(v.GetBool(SkipTombstonedWorkspaces.FieldName)),
+		connector.WithOrganizationFilter(organizationFilter),
+		connector.WithWorkspaceFilter(workspaceFilter),
+	)
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
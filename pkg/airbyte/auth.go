@@ -0,0 +1,163 @@
+package airbyte
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Authentication methods supported by the client.
+const (
+	// AuthMethodClientCredentials exchanges the client ID and secret of an Airbyte application for access tokens.
+	AuthMethodClientCredentials = "client-credentials"
+	// AuthMethodBearerToken sends a pre-issued access token, e.g. one issued by an authenticating proxy.
+	AuthMethodBearerToken = "bearer-token"
+	// AuthMethodKeycloakPassword obtains access tokens from the Keycloak of a self-managed deployment with the password
+	// of a user.
+	AuthMethodKeycloakPassword = "keycloak-password"
+	// AuthMethodNone sends requests unauthenticated, for OSS deployments running without authentication.
+	AuthMethodNone = "none"
+)
+
+const (
+	// DefaultKeycloakRealm is the realm self-managed Airbyte deployments register their users in.
+	DefaultKeycloakRealm = "airbyte"
+	// DefaultKeycloakClientID is the public Keycloak client of the Airbyte web app.
+	DefaultKeycloakClientID = "airbyte-webapp"
+	// defaultUserID is the user Airbyte attributes requests to when authentication is disabled.
+	defaultUserID = "00000000-0000-0000-0000-000000000000"
+)
+
+// Authenticator obtains the access tokens the client authenticates its requests with.
+// Authenticators are created with ClientCredentials, BearerToken, KeycloakPassword or NoAuth.
+type Authenticator interface {
+	// Method returns the authentication method, one of the AuthMethod constants.
+	Method() string
+	// fetchToken obtains a new access token and its expiry. A zero expiry means the token doesn't expire.
+	fetchToken(ctx context.Context, c *Client) (string, time.Time, error)
+}
+
+// ClientCredentials authenticates with the client ID and secret of an Airbyte application.
+func ClientCredentials(clientID string, clientSecret string) Authenticator {
+	return &clientCredentialsAuth{clientID: clientID, clientSecret: clientSecret}
+}
+
+// BearerToken authenticates with a pre-issued access token. The token is used until it expires, if it is a JWT with
+// an expiry, and is never refreshed.
+func BearerToken(token string) Authenticator {
+	return &bearerTokenAuth{token: token}
+}
+
+// KeycloakPassword authenticates as a user of a self-managed deployment with the Keycloak password grant.
+// The realm and client ID default to those of a standard Airbyte installation when empty.
+func KeycloakPassword(realm string, clientID string, username string, password string) Authenticator {
+	if realm == "" {
+		realm = DefaultKeycloakRealm
+	}
+	if clientID == "" {
+		clientID = DefaultKeycloakClientID
+	}
+
+	return &keycloakPasswordAuth{realm: realm, clientID: clientID, username: username, password: password}
+}
+
+// NoAuth sends requests without authentication, for OSS deployments running with authentication disabled.
+func NoAuth() Authenticator {
+	return noAuth{}
+}
+
+type clientCredentialsAuth struct {
+	clientID     string
+	clientSecret string
+}
+
+func (a *clientCredentialsAuth) Method() string {
+	return AuthMethodClientCredentials
+}
+
+func (a *clientCredentialsAuth) fetchToken(ctx context.Context, c *Client) (string, time.Time, error) {
+	tokenResp := &TokenResponse{}
+
+	body := map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     a.clientID,
+		"client_secret": a.clientSecret,
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.urls.token, tokenResp, body, true)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	claims, err := parseJWTClaims(tokenResp.AccessToken)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenResp.AccessToken, time.Unix(claims.ExpiresAt, 0), nil
+}
+
+type bearerTokenAuth struct {
+	token string
+}
+
+func (a *bearerTokenAuth) Method() string {
+	return AuthMethodBearerToken
+}
+
+func (a *bearerTokenAuth) fetchToken(_ context.Context, _ *Client) (string, time.Time, error) {
+	// Tokens issued by proxies aren't necessarily JWTs, those are assumed not to expire.
+	claims, err := parseJWTClaims(a.token)
+	if err != nil || claims.ExpiresAt == 0 {
+		return a.token, time.Time{}, nil
+	}
+
+	return a.token, time.Unix(claims.ExpiresAt, 0), nil
+}
+
+type keycloakPasswordAuth struct {
+	realm    string
+	clientID string
+	username string
+	password string
+}
+
+func (a *keycloakPasswordAuth) Method() string {
+	return AuthMethodKeycloakPassword
+}
+
+func (a *keycloakPasswordAuth) fetchToken(ctx context.Context, c *Client) (string, time.Time, error) {
+	tokenResp := &TokenResponse{}
+
+	body := url.Values{
+		"grant_type": {"password"},
+		"client_id":  {a.clientID},
+		"username":   {a.username},
+		"password":   {a.password},
+	}
+
+	tokenURL := c.buildResourceURL(c.urls.web, keycloakTokenPath, map[string]string{"realm": a.realm}, nil)
+	err := c.doRequest(ctx, http.MethodPost, tokenURL, tokenResp, body, true)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	claims, err := parseJWTClaims(tokenResp.AccessToken)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenResp.AccessToken, time.Unix(claims.ExpiresAt, 0), nil
+}
+
+type noAuth struct{}
+
+func (noAuth) Method() string {
+	return AuthMethodNone
+}
+
+func (noAuth) fetchToken(_ context.Context, _ *Client) (string, time.Time, error) {
+	return "", time.Time{}, fmt.Errorf("airbyte: requests are sent unauthenticated")
+}
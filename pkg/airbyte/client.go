@@ -4,42 +4,92 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Client struct {
-	baseURL      *url.URL
-	accessToken  string
-	clientID     string
-	clientSecret string
-	httpClient   *uhttp.BaseHttpClient
-	tokenExpiry  time.Time
+	endpoints   Endpoints
+	urls        *endpointURLs
+	auth        Authenticator
+	httpClient  *uhttp.BaseHttpClient
+	tokens      *tokenSource
+	retryPolicy RetryPolicy
+
+	rateLimitMu sync.Mutex
+	rateLimit   *v2.RateLimitDescription
 }
 
+// Option configures optional behavior of the Client.
+type Option func(*Client)
+
+// WithRetryPolicy sets how requests rejected by rate limiting are retried.
+func WithRetryPolicy(retryPolicy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = retryPolicy
+	}
+}
+
+// WithEndpoints sets where the web app, the APIs and the token endpoint of the deployment are, see ResolveEndpoints.
+// By default they are those of a self-managed deployment at the hostname of the client.
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(c *Client) {
+		c.endpoints = endpoints
+	}
+}
+
+// Paths of the public API, relative to its root.
 const (
-	getAccessTokenPath               = "/api/v1/applications/token" // #nosec G101
-	getWorkspacePath                 = "/api/public/v1/workspaces/{workspaceId}"
-	listWorkspacesPath               = "/api/public/v1/workspaces"
-	listUsersPath                    = "/api/public/v1/users"
-	listOrganizationsPath            = "/api/public/v1/organizations"
-	listPermissionsPath              = "/api/public/v1/permissions"
-	listWorkspacesByOrganizationPath = "/api/v1/workspaces/list_by_organization_id"
-	listUsersWithAccessInfoPath      = "/api/v1/users/list_access_info_by_workspace_id"
+	getWorkspacePath      = "/workspaces/{workspaceId}"
+	listWorkspacesPath    = "/workspaces"
+	listUsersPath         = "/users"
+	listOrganizationsPath = "/organizations"
+	listPermissionsPath   = "/permissions"
+	listApplicationsPath  = "/applications"
+	getApplicationPath    = "/applications/{applicationId}"
+	createApplicationPath = "/applications"
+	deleteApplicationPath = "/applications/{applicationId}"
+	listConnectionsPath   = "/connections"
+	listSourcesPath       = "/sources"
+	listDestinationsPath  = "/destinations"
+	createPermissionPath  = "/permissions"
+	updatePermissionPath  = "/permissions/{permissionId}"
+	deletePermissionPath  = "/permissions/{permissionId}"
 )
 
-func NewClient(ctx context.Context, hostname string, clientID string, clientSecret string) (*Client, error) {
-	baseURL, err := url.Parse(hostname)
-	if err != nil {
-		return nil, err
-	}
+// Paths of the config API, relative to its root.
+const (
+	listWorkspacesByOrganizationPath = "/workspaces/list_by_organization_id"
+	listUsersWithAccessInfoPath      = "/users/list_access_info_by_workspace_id"
+	listUsersByOrganizationPath      = "/users/list_by_organization_id"
+	createUserInvitationPath         = "/user_invitations/create"
+	listPendingInvitationsPath       = "/user_invitations/list_pending"
+	cancelUserInvitationPath         = "/user_invitations/cancel"
+	getInstanceConfigurationPath     = "/instance_configuration"
+	getHealthPath                    = "/health"
+	listInstanceAdminsPath           = "/users/list_instance_admins"
+	getUserPath                      = "/users/get"
+	getUserByAuthIDPath              = "/users/get_by_auth_id"
+)
 
+// keycloakTokenPath is the token endpoint of Keycloak, relative to the web app of self-managed deployments.
+const keycloakTokenPath = "/auth/realms/{realm}/protocol/openid-connect/token" // #nosec G101
+
+// NewClient returns a client of the Airbyte deployment at hostname, authenticating its requests with auth.
+// The hostname is ignored if the endpoints of the deployment are set with WithEndpoints.
+func NewClient(ctx context.Context, hostname string, auth Authenticator, opts ...Option) (*Client, error) {
 	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
 	if err != nil {
 		return nil, err
@@ -50,43 +100,61 @@ func NewClient(ctx context.Context, hostname string, clientID string, clientSecr
 		return nil, err
 	}
 
-	return &Client{
-		httpClient:   wrapper,
-		baseURL:      baseURL,
-		clientID:     clientID,
-		clientSecret: clientSecret,
-	}, nil
-}
+	client := &Client{
+		httpClient:  wrapper,
+		auth:        auth,
+		retryPolicy: DefaultRetryPolicy,
+	}
+	if auth.Method() != AuthMethodNone {
+		client.tokens = newTokenSource(ctx, client.GetAccessToken)
+	}
 
-// Access token lifetimes vary by Airbyte deployment type/version:
-// • Open Source/Cloud: 3 minutes
-// • Enterprise: 24 hours
-//
-// This function ensures that the access token is valid before making requests.
-// It refreshes the token if it's expired or about to expire.
-//
-// The token is refreshed when:
-// • The token is not set (first time access)
-// • The token is expired (3 minutes/24 hours)
-// • The token expires in the next 30 seconds
-//
-// This ensures that the token is always fresh when needed.
-//
-// Reference: https://reference.airbyte.com/reference/authentication
-func (c *Client) ensureValidToken(ctx context.Context) error {
-	// Check if token needs refresh (with 30s buffer).
-	if c.accessToken == "" || time.Now().Add(30*time.Second).After(c.tokenExpiry) {
-		// Get new token.
-		token, expiry, err := c.GetAccessToken(ctx)
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	if client.endpoints == (Endpoints{}) {
+		client.endpoints, err = ResolveEndpoints(DeploymentProfileSelfManaged, hostname, Endpoints{})
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		c.accessToken = token
-		c.tokenExpiry = expiry
+	client.urls, err = parseEndpoints(client.endpoints)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return client, nil
+}
+
+// ClientID returns the client ID of the application the client authenticates with, or an empty string if it doesn't
+// authenticate with an application.
+func (c *Client) ClientID() string {
+	if auth, ok := c.auth.(*clientCredentialsAuth); ok {
+		return auth.clientID
+	}
+
+	return ""
+}
+
+// AuthMethod returns the method the client authenticates with, one of the AuthMethod constants.
+func (c *Client) AuthMethod() string {
+	return c.auth.Method()
+}
+
+// Host returns the host of the Airbyte deployment the client connects to.
+func (c *Client) Host() string {
+	if c.urls.web.Host == "" {
+		return c.urls.web.String()
+	}
+
+	return c.urls.web.Host
+}
+
+// WorkspaceURL returns the URL of the workspace in the Airbyte UI.
+func (c *Client) WorkspaceURL(workspaceID string) string {
+	return c.buildResourceURL(c.urls.web, "/workspaces/{workspaceId}", map[string]string{"workspaceId": workspaceID}, nil).String()
 }
 
 // -------------------------------------------------------------------------------------------------
@@ -95,45 +163,50 @@ func (c *Client) ensureValidToken(ctx context.Context) error {
 
 // GetAccessToken fetches a new access token from Airbyte.
 //
-// This function handles the process of obtaining a new access token from Airbyte.
-// It constructs the request URL, sets up the request body, and makes the HTTP POST request.
+// This function obtains the token with the authenticator of the client, e.g. by exchanging the application
+// credentials at the token endpoint of Airbyte or by requesting one from Keycloak.
 //
-// The token response is parsed to extract the access token and its expiration time.
-//
-// The function returns the new access token and its expiration time.
+// The function returns the new access token and its expiration time. A zero expiration time means the token
+// doesn't expire.
 func (c *Client) GetAccessToken(ctx context.Context) (string, time.Time, error) {
-	tokenResp := &TokenResponse{}
+	return c.auth.fetchToken(ctx, c)
+}
 
-	body := map[string]string{
-		"grant_type":    "client_credentials",
-		"client_id":     c.clientID,
-		"client_secret": c.clientSecret,
+// GetCurrentUserID returns the ID of the Airbyte user the connector is authenticated as.
+//
+// Airbyte application tokens are issued on behalf of the user that owns the application,
+// so the subject of the access token identifies that user. The subject of other tokens, e.g. Keycloak tokens,
+// identifies the user at the authentication provider, which is resolved to the Airbyte user. Deployments without
+// authentication attribute every request to the default user.
+func (c *Client) GetCurrentUserID(ctx context.Context) (string, error) {
+	if c.tokens == nil {
+		return defaultUserID, nil
 	}
 
-	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(getAccessTokenPath, nil, nil), tokenResp, body, true)
+	token, err := c.tokens.Token(ctx)
 	if err != nil {
-		return "", time.Time{}, err
+		return "", err
 	}
 
-	// Parse JWT token to get expiry
-	parts := strings.Split(tokenResp.AccessToken, ".")
-	if len(parts) != 3 {
-		return "", time.Time{}, fmt.Errorf("invalid JWT token format")
+	claims, err := parseJWTClaims(token)
+	if err != nil {
+		return "", err
 	}
 
-	// Decode the claims (middle part)
-	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
-	if err != nil {
-		return "", time.Time{}, fmt.Errorf("error decoding JWT claims: %w", err)
+	if c.auth.Method() == AuthMethodClientCredentials {
+		return claims.Subject, nil
 	}
 
-	var claims JWTClaims
-	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
-		return "", time.Time{}, fmt.Errorf("error parsing JWT claims: %w", err)
+	user, err := c.GetUserByAuthID(ctx, claims.Subject)
+	if err != nil {
+		// Pre-issued tokens may as well be application tokens, whose subject already is the Airbyte user.
+		if status.Code(err) == codes.NotFound && c.auth.Method() == AuthMethodBearerToken {
+			return claims.Subject, nil
+		}
+		return "", err
 	}
 
-	expiry := time.Unix(claims.ExpiresAt, 0)
-	return tokenResp.AccessToken, expiry, nil
+	return user.UserID, nil
 }
 
 // ListAllWorkspaces fetches all workspaces from Airbyte.
@@ -155,7 +228,91 @@ func (c *Client) ListAllWorkspaces(ctx context.Context, limit uint64, offset str
 		"offset": offset,
 	}
 
-	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(listWorkspacesPath, nil, queryParams), resp, nil, false)
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listWorkspacesPath, nil, queryParams), resp, nil, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp.Data, GetOffsetForTheNextPageFromURL(resp.Next), nil
+}
+
+// ListConnectionsByWorkspace fetches the connections of a workspace from Airbyte.
+//
+// This function retrieves the connections (sync pipelines between a source and a destination) of a workspace.
+// It uses pagination to handle large datasets efficiently.
+//
+// The function returns a list of connections and the offset for the next page of connections.
+func (c *Client) ListConnectionsByWorkspace(ctx context.Context, workspaceId string, limit uint64, offset string) ([]*ConnectionResponse, string, error) {
+	resp := &APIResponse[[]*ConnectionResponse]{}
+
+	// If offset is empty, set it to 0.
+	if offset == "" {
+		offset = "0"
+	}
+
+	queryParams := map[string]string{
+		"workspaceIds": workspaceId,
+		"limit":        fmt.Sprintf("%d", limit),
+		"offset":       offset,
+	}
+
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listConnectionsPath, nil, queryParams), resp, nil, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp.Data, GetOffsetForTheNextPageFromURL(resp.Next), nil
+}
+
+// ListSourcesByWorkspace fetches the sources of a workspace from Airbyte.
+//
+// This function retrieves the configured sources of a workspace.
+// It uses pagination to handle large datasets efficiently.
+//
+// The function returns a list of sources and the offset for the next page of sources.
+func (c *Client) ListSourcesByWorkspace(ctx context.Context, workspaceId string, limit uint64, offset string) ([]*SourceResponse, string, error) {
+	resp := &APIResponse[[]*SourceResponse]{}
+
+	// If offset is empty, set it to 0.
+	if offset == "" {
+		offset = "0"
+	}
+
+	queryParams := map[string]string{
+		"workspaceIds": workspaceId,
+		"limit":        fmt.Sprintf("%d", limit),
+		"offset":       offset,
+	}
+
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listSourcesPath, nil, queryParams), resp, nil, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp.Data, GetOffsetForTheNextPageFromURL(resp.Next), nil
+}
+
+// ListDestinationsByWorkspace fetches the destinations of a workspace from Airbyte.
+//
+// This function retrieves the configured destinations of a workspace.
+// It uses pagination to handle large datasets efficiently.
+//
+// The function returns a list of destinations and the offset for the next page of destinations.
+func (c *Client) ListDestinationsByWorkspace(ctx context.Context, workspaceId string, limit uint64, offset string) ([]*DestinationResponse, string, error) {
+	resp := &APIResponse[[]*DestinationResponse]{}
+
+	// If offset is empty, set it to 0.
+	if offset == "" {
+		offset = "0"
+	}
+
+	queryParams := map[string]string{
+		"workspaceIds": workspaceId,
+		"limit":        fmt.Sprintf("%d", limit),
+		"offset":       offset,
+	}
+
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listDestinationsPath, nil, queryParams), resp, nil, false)
 	if err != nil {
 		return nil, "", err
 	}
@@ -176,7 +333,7 @@ func (c *Client) ListUsersByOrganization(ctx context.Context, orgId string) ([]*
 	}
 
 	// This endpoint doesn't support pagination.
-	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(listUsersPath, nil, queryParams), resp, nil, false)
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listUsersPath, nil, queryParams), resp, nil, false)
 	if err != nil {
 		return nil, err
 	}
@@ -192,13 +349,35 @@ func (c *Client) ListUsersByOrganization(ctx context.Context, orgId string) ([]*
 func (c *Client) ListPermissionsByUserAndOrganization(ctx context.Context, userId string, orgId string) ([]*Permission, error) {
 	resp := &APIResponse[[]*Permission]{}
 
-	pathParams := map[string]string{
+	queryParams := map[string]string{
+		"userId":         userId,
+		"organizationId": orgId,
+	}
+
+	// This endpoint doesn't support pagination.
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listPermissionsPath, nil, queryParams), resp, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// ListPermissionsByUser fetches all permissions of a user from Airbyte.
+//
+// This function retrieves the permissions of a user across every scope, including instance-wide permissions such as
+// instance_admin that don't belong to any organization.
+//
+// The function returns a list of permissions.
+func (c *Client) ListPermissionsByUser(ctx context.Context, userId string) ([]*Permission, error) {
+	resp := &APIResponse[[]*Permission]{}
+
+	queryParams := map[string]string{
 		"userId": userId,
-		"orgId":  orgId,
 	}
 
 	// This endpoint doesn't support pagination.
-	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(listPermissionsPath, pathParams, nil), resp, nil, false)
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listPermissionsPath, nil, queryParams), resp, nil, false)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +394,7 @@ func (c *Client) ListOrganizations(ctx context.Context) ([]*Organization, error)
 	resp := &APIResponse[[]*Organization]{}
 
 	// This endpoint doesn't support pagination.
-	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(listOrganizationsPath, nil, nil), resp, nil, false)
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listOrganizationsPath, nil, nil), resp, nil, false)
 	if err != nil {
 		return nil, err
 	}
@@ -223,6 +402,159 @@ func (c *Client) ListOrganizations(ctx context.Context) ([]*Organization, error)
 	return resp.Data, nil
 }
 
+// CreateWorkspacePermission creates a workspace permission for a user in Airbyte.
+//
+// This function grants the given permission type to the user in the specified workspace.
+//
+// The function returns the created permission.
+func (c *Client) CreateWorkspacePermission(ctx context.Context, userId string, workspaceId string, permissionType string) (*Permission, error) {
+	resp := &Permission{}
+
+	body := map[string]string{
+		"userId":         userId,
+		"workspaceId":    workspaceId,
+		"permissionType": permissionType,
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.publicAPI, createPermissionPath, nil, nil), resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateOrganizationPermission creates an organization permission for a user in Airbyte.
+//
+// This function grants the given permission type to the user in the specified organization.
+// Airbyte allows a single organization-scoped permission per user, so callers should update an existing one instead.
+//
+// The function returns the created permission.
+func (c *Client) CreateOrganizationPermission(ctx context.Context, userId string, orgId string, permissionType string) (*Permission, error) {
+	resp := &Permission{}
+
+	body := map[string]string{
+		"userId":         userId,
+		"organizationId": orgId,
+		"permissionType": permissionType,
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.publicAPI, createPermissionPath, nil, nil), resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// UpdatePermission updates the permission type of an existing permission in Airbyte.
+//
+// This function changes the role of an existing permission while keeping its scope.
+//
+// The function returns the updated permission.
+func (c *Client) UpdatePermission(ctx context.Context, permissionId string, permissionType string) (*Permission, error) {
+	resp := &Permission{}
+
+	pathParams := map[string]string{
+		"permissionId": permissionId,
+	}
+
+	body := map[string]string{
+		"permissionType": permissionType,
+	}
+
+	err := c.doRequest(ctx, http.MethodPatch, c.buildResourceURL(c.urls.publicAPI, updatePermissionPath, pathParams, nil), resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// DeletePermission deletes a permission from Airbyte.
+//
+// This function removes the permission, revoking the access it granted.
+func (c *Client) DeletePermission(ctx context.Context, permissionId string) error {
+	pathParams := map[string]string{
+		"permissionId": permissionId,
+	}
+
+	// This endpoint returns an empty body on success.
+	return c.doRequest(ctx, http.MethodDelete, c.buildResourceURL(c.urls.publicAPI, deletePermissionPath, pathParams, nil), nil, nil, false)
+}
+
+// ListApplications fetches the applications of the authenticated user from Airbyte.
+//
+// This function retrieves the applications (client ID/client secret pairs) owned by the user the connector is
+// authenticated as. Airbyte doesn't expose the applications of other users.
+//
+// The function returns a list of applications.
+func (c *Client) ListApplications(ctx context.Context) ([]*Application, error) {
+	resp := &ApplicationListResponse{}
+
+	// This endpoint doesn't support pagination.
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, listApplicationsPath, nil, nil), resp, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Applications, nil
+}
+
+// GetApplication fetches an application of the authenticated user from Airbyte.
+//
+// The function returns the application.
+func (c *Client) GetApplication(ctx context.Context, applicationId string) (*Application, error) {
+	resp := &Application{}
+
+	pathParams := map[string]string{
+		"applicationId": applicationId,
+	}
+
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.publicAPI, getApplicationPath, pathParams, nil), resp, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateApplication creates an application for the authenticated user in Airbyte.
+//
+// This function creates a new client ID/client secret pair with the given name.
+//
+// The function returns the created application, including its client secret.
+func (c *Client) CreateApplication(ctx context.Context, name string) (*Application, error) {
+	resp := &Application{}
+
+	body := map[string]string{
+		"name": name,
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.publicAPI, createApplicationPath, nil, nil), resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// DeleteApplication deletes an application of the authenticated user from Airbyte.
+//
+// This function invalidates the client ID/client secret pair of the application.
+func (c *Client) DeleteApplication(ctx context.Context, applicationId string) error {
+	pathParams := map[string]string{
+		"applicationId": applicationId,
+	}
+
+	err := c.doRequest(ctx, http.MethodDelete, c.buildResourceURL(c.urls.publicAPI, deleteApplicationPath, pathParams, nil), nil, nil, false)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // -------------------------------------------------------------------------------------------------
 // PRIVATE API ENDPOINTS
 // -------------------------------------------------------------------------------------------------
@@ -244,7 +576,7 @@ func (c *Client) ListWorkspacesByOrganization(ctx context.Context, orgId string,
 		},
 	}
 
-	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(listWorkspacesByOrganizationPath, nil, nil), resp, body, false)
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, listWorkspacesByOrganizationPath, nil, nil), resp, body, false)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -271,7 +603,7 @@ func (c *Client) ListUsersWithAccessInfoByWorkspace(ctx context.Context, workspa
 	}
 
 	// This endpoint doesn't support pagination.
-	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(listUsersWithAccessInfoPath, nil, nil), resp, body, false)
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, listUsersWithAccessInfoPath, nil, nil), resp, body, false)
 	if err != nil {
 		return nil, err
 	}
@@ -279,14 +611,198 @@ func (c *Client) ListUsersWithAccessInfoByWorkspace(ctx context.Context, workspa
 	return resp.UsersWithAccess, nil
 }
 
+// ListOrganizationUsersWithPermissions fetches the users of an organization together with their organization
+// permission from Airbyte.
+//
+// This function retrieves the members of an organization and the organization-scoped permission each of them holds,
+// so the roles of all members are known without a request per user.
+// It uses pagination to handle large datasets efficiently.
+//
+// The function returns a list of organization users and the row offset of the next page, 0 if there are no more pages.
+func (c *Client) ListOrganizationUsersWithPermissions(ctx context.Context, orgId string, pageSize uint64, rowOffset uint64) ([]OrganizationUserReadResponse, uint64, error) {
+	resp := &OrganizationUserReadListResponse{}
+
+	body := map[string]interface{}{
+		"organizationId": orgId,
+		"pagination": map[string]interface{}{
+			"pageSize":  pageSize,
+			"rowOffset": rowOffset,
+		},
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, listUsersByOrganizationPath, nil, nil), resp, body, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if uint64(len(resp.Users)) < pageSize {
+		return resp.Users, 0, nil
+	}
+
+	nextRowOffset := rowOffset + pageSize
+
+	return resp.Users, nextRowOffset, nil
+}
+
+// GetHealth checks whether the config API of the Airbyte deployment is available.
+//
+// This function calls the health endpoint of the config API, which doesn't require authentication.
+//
+// The function returns the health of the deployment.
+func (c *Client) GetHealth(ctx context.Context) (*HealthCheckResponse, error) {
+	resp := &HealthCheckResponse{}
+
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.configAPI, getHealthPath, nil, nil), resp, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetInstanceConfiguration fetches the configuration of the Airbyte instance.
+//
+// This function retrieves the edition, version and URL of a self-managed deployment.
+//
+// The function returns the instance configuration.
+func (c *Client) GetInstanceConfiguration(ctx context.Context) (*InstanceConfigurationResponse, error) {
+	resp := &InstanceConfigurationResponse{}
+
+	err := c.doRequest(ctx, http.MethodGet, c.buildResourceURL(c.urls.configAPI, getInstanceConfigurationPath, nil, nil), resp, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ListInstanceAdmins fetches the instance administrators of the Airbyte instance.
+//
+// This function retrieves every user holding the instance_admin permission, including administrators that don't
+// belong to any organization. Only self-managed deployments have instance administrators.
+//
+// The function returns a list of instance administrators.
+func (c *Client) ListInstanceAdmins(ctx context.Context) ([]InstanceAdminReadResponse, error) {
+	resp := &InstanceAdminReadListResponse{}
+
+	// This endpoint doesn't support pagination.
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, listInstanceAdminsPath, nil, nil), resp, map[string]string{}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Users, nil
+}
+
+// GetUser fetches the details of an Airbyte user.
+//
+// This function retrieves the authentication provider, status and default workspace of a user, which the public
+// users endpoint doesn't return.
+//
+// The function returns the user details.
+func (c *Client) GetUser(ctx context.Context, userId string) (*UserReadResponse, error) {
+	resp := &UserReadResponse{}
+
+	body := map[string]string{
+		"userId": userId,
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, getUserPath, nil, nil), resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetUserByAuthID fetches the Airbyte user with the given ID of the authentication provider, e.g. the Keycloak
+// subject of an access token.
+//
+// The function returns the user details.
+func (c *Client) GetUserByAuthID(ctx context.Context, authUserID string) (*UserReadResponse, error) {
+	resp := &UserReadResponse{}
+
+	body := map[string]string{
+		"authUserId": authUserID,
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, getUserByAuthIDPath, nil, nil), resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateUserInvitation invites an email address into an Airbyte organization or workspace.
+//
+// This function sends an invitation for the given scope type ("organization" or "workspace") and scope ID with the
+// permission type the user will receive once the invitation is accepted.
+// Airbyte adds users directly instead of inviting them when they already have an account that can be granted access.
+//
+// The function returns the invitation code and whether the user was added directly.
+func (c *Client) CreateUserInvitation(ctx context.Context, invitedEmail string, scopeType string, scopeId string, permissionType string) (*UserInvitationCreateResponse, error) {
+	resp := &UserInvitationCreateResponse{}
+
+	body := map[string]string{
+		"invitedEmail":   invitedEmail,
+		"scopeType":      scopeType,
+		"scopeId":        scopeId,
+		"permissionType": permissionType,
+	}
+
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, createUserInvitationPath, nil, nil), resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ListPendingInvitations fetches the invitations of an Airbyte organization or workspace that weren't accepted yet.
+//
+// This function retrieves the pending invitations for the given scope type ("organization" or "workspace") and scope
+// ID, with the permission type each invited user will receive.
+//
+// The function returns a list of pending invitations.
+func (c *Client) ListPendingInvitations(ctx context.Context, scopeType string, scopeId string) ([]*UserInvitationReadResponse, error) {
+	var resp []*UserInvitationReadResponse
+
+	body := map[string]string{
+		"scopeType": scopeType,
+		"scopeId":   scopeId,
+	}
+
+	// This endpoint doesn't support pagination.
+	err := c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, listPendingInvitationsPath, nil, nil), &resp, body, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CancelUserInvitation cancels a pending invitation, so it can no longer be accepted.
+func (c *Client) CancelUserInvitation(ctx context.Context, inviteCode string) error {
+	body := map[string]string{
+		"inviteCode": inviteCode,
+	}
+
+	return c.doRequest(ctx, http.MethodPost, c.buildResourceURL(c.urls.configAPI, cancelUserInvitationPath, nil, nil), nil, body, false)
+}
+
 // -------------------------------------------------------------------------------------------------
 // PRIVATE HELPER FUNCTIONS
 // -------------------------------------------------------------------------------------------------
 
 // doRequest handles HTTP requests with authentication and optional pagination.
 //
-// This function constructs a request with the specified HTTP method, URL, and optional data.
-// It also handles authentication by adding an authorization header if not skipping authentication.
+// This function constructs a request with the specified HTTP method, URL, and optional data, which is sent as a form
+// if it is url.Values and as JSON otherwise.
+// It also handles authentication by adding an authorization header, unless authentication is skipped or the client
+// is configured without authentication.
+// If Airbyte rejects the access token before its expiry, e.g. because it was revoked, the token is refreshed and
+// the request is retried once.
 //
 // The function returns an error if the request fails or if the response cannot be parsed.
 func (c *Client) doRequest(
@@ -296,22 +812,97 @@ func (c *Client) doRequest(
 	response interface{},
 	data interface{},
 	skipAuth bool,
+) error {
+	if skipAuth || c.tokens == nil {
+		return c.sendRequest(ctx, method, urlAddress, response, data, "")
+	}
+
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.sendRequest(ctx, method, urlAddress, response, data, token)
+	if status.Code(err) != codes.Unauthenticated {
+		return err
+	}
+
+	ctxzap.Extract(ctx).Debug("airbyte: access token rejected, refreshing it and retrying the request", zap.String("url", urlAddress.String()))
+
+	c.tokens.invalidate(token)
+	token, err = c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(ctx, method, urlAddress, response, data, token)
+}
+
+// sendRequest sends an HTTP request, authenticated with the access token unless it is empty.
+//
+// Requests rejected with 429 Too Many Requests, and idempotent requests rejected with 503 Service Unavailable, are
+// retried with backoff and jitter, honoring the Retry-After header, as long as the retry policy budget allows it.
+func (c *Client) sendRequest(
+	ctx context.Context,
+	method string,
+	urlAddress *url.URL,
+	response interface{},
+	data interface{},
+	accessToken string,
+) error {
+	var waited time.Duration
+	for retry := 0; ; retry++ {
+		err := c.sendRequestOnce(ctx, method, urlAddress, response, data, accessToken)
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !isRetryableStatus(method, apiErr.StatusCode) || retry >= c.retryPolicy.MaxRetries {
+			return err
+		}
+
+		delay := c.retryPolicy.retryDelay(retry, apiErr.RetryAfter)
+		if waited+delay > c.retryPolicy.MaxWait {
+			return err
+		}
+		waited += delay
+
+		ctxzap.Extract(ctx).Debug(
+			"airbyte: request rate limited, retrying",
+			zap.String("url", urlAddress.String()),
+			zap.Int("status_code", apiErr.StatusCode),
+			zap.Duration("delay", delay),
+			zap.Int("retry", retry+1),
+		)
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// sendRequestOnce sends a single HTTP request, authenticated with the access token unless it is empty.
+func (c *Client) sendRequestOnce(
+	ctx context.Context,
+	method string,
+	urlAddress *url.URL,
+	response interface{},
+	data interface{},
+	accessToken string,
 ) error {
 	reqOptions := []uhttp.RequestOption{
 		uhttp.WithContentType("application/json"),
 		uhttp.WithAccept("application/json"),
 	}
 
-	// Only add authorization header if not skipping auth.
-	if !skipAuth {
-		if err := c.ensureValidToken(ctx); err != nil {
-			return err
-		}
-		reqOptions = append(reqOptions, uhttp.WithHeader("Authorization", "Bearer "+c.accessToken))
+	if accessToken != "" {
+		reqOptions = append(reqOptions, uhttp.WithHeader("Authorization", "Bearer "+accessToken))
 	}
 
-	if data != nil {
-		reqOptions = append(reqOptions, uhttp.WithJSONBody(data))
+	switch body := data.(type) {
+	case nil:
+	case url.Values:
+		reqOptions = append(reqOptions, uhttp.WithFormBody(body.Encode()))
+	default:
+		reqOptions = append(reqOptions, uhttp.WithJSONBody(body))
 	}
 
 	req, err := c.httpClient.NewRequest(ctx, method, urlAddress, reqOptions...)
@@ -319,13 +910,19 @@ func (c *Client) doRequest(
 		return err
 	}
 
-	doOptions := []uhttp.DoOption{}
+	apiErr := &APIError{}
+	doOptions := []uhttp.DoOption{withAPIError(apiErr), c.withRateLimitTracking()}
 	if response != nil {
 		doOptions = append(doOptions, uhttp.WithJSONResponse(response))
 	}
 
 	resp, err := c.httpClient.Do(req, doOptions...)
 	if err != nil {
+		// Error responses are reported as a decoded APIError, other failures (e.g. timeouts) are passed through.
+		if apiErr.StatusCode != 0 {
+			apiErr.err = err
+			return apiErr
+		}
 		return err
 	}
 
@@ -334,17 +931,19 @@ func (c *Client) doRequest(
 	return nil
 }
 
-// The buildResourceURL function constructs an absolute URL by formatting a resource path.
+// The buildResourceURL function constructs an absolute URL by formatting a resource path under an API root.
 //
-// This function constructs a URL by replacing path parameters with their actual values and adding query parameters.
+// This function constructs a URL by replacing path parameters with their actual values, appending the path to the
+// root and adding query parameters.
 //
 // The function returns the constructed URL.
 // Example:
-// pathTemplate: "/api/v1/workspaces/{workspaceId}"
+// root: "https://airbyte.example.com/api/v1"
+// pathTemplate: "/workspaces/{workspaceId}"
 // pathParams: map[string]string{"workspaceId": "123"}
 // queryParams: map[string]string{"limit": "10", "offset": "0"}
-// The function returns the constructed URL: "/api/v1/workspaces/123?limit=10&offset=0".
-func (c *Client) buildResourceURL(pathTemplate string, pathParams map[string]string, queryParams map[string]string) *url.URL {
+// The function returns the constructed URL: "https://airbyte.example.com/api/v1/workspaces/123?limit=10&offset=0".
+func (c *Client) buildResourceURL(root *url.URL, pathTemplate string, pathParams map[string]string, queryParams map[string]string) *url.URL {
 	finalPath := pathTemplate
 
 	// Replace path parameters using named placeholders
@@ -355,8 +954,8 @@ func (c *Client) buildResourceURL(pathTemplate string, pathParams map[string]str
 		}
 	}
 
-	// Create URL from base and path
-	u := c.baseURL.ResolveReference(&url.URL{Path: finalPath})
+	// Create URL from root and path
+	u := root.ResolveReference(&url.URL{Path: strings.TrimSuffix(root.Path, "/") + finalPath})
 
 	// Add query parameters if provided
 	if len(queryParams) > 0 {
@@ -370,6 +969,27 @@ func (c *Client) buildResourceURL(pathTemplate string, pathParams map[string]str
 	return u
 }
 
+// parseJWTClaims decodes the claims of a JWT access token without verifying its signature.
+func parseJWTClaims(token string) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT token format")
+	}
+
+	// Decode the claims (middle part)
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding JWT claims: %w", err)
+	}
+
+	var claims JWTClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("error parsing JWT claims: %w", err)
+	}
+
+	return &claims, nil
+}
+
 // GetOffsetForTheNextPageFromURL extracts the offset from a URL.
 //
 // This function parses a URL and extracts the offset parameter from the query string.
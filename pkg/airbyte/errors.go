@@ -0,0 +1,132 @@
+package airbyte
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDHeader is the response header Airbyte identifies a request with.
+const requestIDHeader = "X-Request-Id"
+
+// APIError is an error response returned by Airbyte.
+//
+// The public API returns problem+json bodies (type, title, detail, documentationUrl), while the config API
+// (/api/v1) returns a message with the name of the exception that was raised. Both shapes are decoded into APIError.
+//
+// APIError maps the HTTP status to a gRPC status code, so callers can tell e.g. a missing permission
+// (codes.PermissionDenied) apart from other failures with status.Code.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	RequestID  string `json:"-"`
+	// RetryAfter is the delay requested by the Retry-After header, if any.
+	RetryAfter time.Duration `json:"-"`
+
+	// Public API problem details.
+	Type             string `json:"type"`
+	Title            string `json:"title"`
+	Detail           string `json:"detail"`
+	DocumentationURL string `json:"documentationUrl"`
+
+	// Config API exception details.
+	Message            string `json:"message"`
+	ExceptionClassName string `json:"exceptionClassName"`
+
+	// err is the error returned by the HTTP client for the response.
+	err error
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "airbyte: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+
+	for _, part := range []string{e.Title, e.Detail, e.Message} {
+		if part != "" {
+			b.WriteString(": ")
+			b.WriteString(part)
+		}
+	}
+
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request id %s)", e.RequestID)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the error returned by the HTTP client for the response.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus maps the HTTP status of the error to a gRPC status.
+// Details attached by the HTTP client, such as rate limit descriptions, are kept.
+func (e *APIError) GRPCStatus() *status.Status {
+	st := status.New(grpcCodeFromHTTPStatus(e.StatusCode), e.Error())
+
+	if wrapped, ok := status.FromError(e.err); ok {
+		for _, detail := range wrapped.Proto().GetDetails() {
+			if withDetails, err := st.WithDetails(detail); err == nil {
+				st = withDetails
+			}
+		}
+	}
+
+	return st
+}
+
+// grpcCodeFromHTTPStatus maps an Airbyte HTTP status to the closest gRPC code.
+func grpcCodeFromHTTPStatus(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.Unavailable
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	}
+
+	if statusCode >= 500 {
+		return codes.Unavailable
+	}
+
+	return codes.Unknown
+}
+
+// withAPIError decodes error responses into apiErr.
+// It never fails the request itself, the HTTP client already reports the failure.
+func withAPIError(apiErr *APIError) uhttp.DoOption {
+	return func(resp *uhttp.WrapperResponse) error {
+		if resp.StatusCode < http.StatusMultipleChoices {
+			return nil
+		}
+
+		apiErr.StatusCode = resp.StatusCode
+		apiErr.RequestID = resp.Header.Get(requestIDHeader)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header, time.Now())
+
+		if uhttp.IsJSONContentType(resp.Header.Get(uhttp.ContentType)) && len(resp.Body) > 0 {
+			// Undecodable bodies still produce an APIError with the status code.
+			_ = json.Unmarshal(resp.Body, apiErr)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package airbyte
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCCodeFromHTTPStatus(t *testing.T) {
+	testCases := []struct {
+		statusCode int
+		code       codes.Code
+	}{
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusUnprocessableEntity, codes.InvalidArgument},
+		{http.StatusUnauthorized, codes.Unauthenticated},
+		{http.StatusForbidden, codes.PermissionDenied},
+		{http.StatusNotFound, codes.NotFound},
+		{http.StatusConflict, codes.AlreadyExists},
+		{http.StatusRequestTimeout, codes.DeadlineExceeded},
+		{http.StatusTooManyRequests, codes.Unavailable},
+		{http.StatusNotImplemented, codes.Unimplemented},
+		{http.StatusInternalServerError, codes.Unavailable},
+		{http.StatusBadGateway, codes.Unavailable},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{http.StatusTeapot, codes.Unknown},
+	}
+
+	for _, tc := range testCases {
+		if code := grpcCodeFromHTTPStatus(tc.statusCode); code != tc.code {
+			t.Errorf("%d: expected %s, got %s", tc.statusCode, tc.code, code)
+		}
+	}
+}
+
+func TestAPIErrorDecoding(t *testing.T) {
+	testCases := []struct {
+		message     string
+		statusCode  int
+		contentType string
+		body        string
+		code        codes.Code
+		expected    APIError
+		errorText   string
+	}{
+		{
+			message:     "public API problem",
+			statusCode:  http.StatusForbidden,
+			contentType: "application/problem+json",
+			body:        `{"type": "https://reference.airbyte.com/reference/errors#forbidden", "title": "forbidden", "detail": "Caller does not have the required ORGANIZATION_ADMIN permissions.", "documentationUrl": "https://reference.airbyte.com/reference/errors"}`,
+			code:        codes.PermissionDenied,
+			expected: APIError{
+				Type:             "https://reference.airbyte.com/reference/errors#forbidden",
+				Title:            "forbidden",
+				Detail:           "Caller does not have the required ORGANIZATION_ADMIN permissions.",
+				DocumentationURL: "https://reference.airbyte.com/reference/errors",
+			},
+			errorText: "airbyte: 403 Forbidden: forbidden: Caller does not have the required ORGANIZATION_ADMIN permissions. (request id request-1)",
+		},
+		{
+			message:     "config API exception",
+			statusCode:  http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"message": "Could not find configuration for STANDARD_WORKSPACE: workspace-1.", "exceptionClassName": "io.airbyte.config.persistence.ConfigNotFoundException"}`,
+			code:        codes.NotFound,
+			expected: APIError{
+				Message:            "Could not find configuration for STANDARD_WORKSPACE: workspace-1.",
+				ExceptionClassName: "io.airbyte.config.persistence.ConfigNotFoundException",
+			},
+			errorText: "airbyte: 404 Not Found: Could not find configuration for STANDARD_WORKSPACE: workspace-1. (request id request-1)",
+		},
+		{
+			message:    "empty body",
+			statusCode: http.StatusUnauthorized,
+			code:       codes.Unauthenticated,
+			errorText:  "airbyte: 401 Unauthorized (request id request-1)",
+		},
+		{
+			message:     "undecodable body",
+			statusCode:  http.StatusBadGateway,
+			contentType: "application/json",
+			body:        `<html>bad gateway</html>`,
+			code:        codes.Unavailable,
+			errorText:   "airbyte: 502 Bad Gateway (request id request-1)",
+		},
+		{
+			message:     "non JSON body",
+			statusCode:  http.StatusConflict,
+			contentType: "text/plain",
+			body:        `{"message": "ignored"}`,
+			code:        codes.AlreadyExists,
+			errorText:   "airbyte: 409 Conflict (request id request-1)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			ctx := context.Background()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				w.Header().Set(requestIDHeader, "request-1")
+				w.WriteHeader(tc.statusCode)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(ctx, server.URL, NoAuth(), WithRetryPolicy(RetryPolicy{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.ListOrganizations(ctx)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+
+			if apiErr.StatusCode != tc.statusCode || apiErr.RequestID != "request-1" {
+				t.Fatalf("unexpected status %d and request id %q", apiErr.StatusCode, apiErr.RequestID)
+			}
+			if apiErr.Type != tc.expected.Type ||
+				apiErr.Title != tc.expected.Title ||
+				apiErr.Detail != tc.expected.Detail ||
+				apiErr.DocumentationURL != tc.expected.DocumentationURL ||
+				apiErr.Message != tc.expected.Message ||
+				apiErr.ExceptionClassName != tc.expected.ExceptionClassName {
+				t.Fatalf("unexpected decoded error %+v", apiErr)
+			}
+
+			if code := status.Code(err); code != tc.code {
+				t.Fatalf("expected code %s, got %s", tc.code, code)
+			}
+			if !strings.HasPrefix(err.Error(), tc.errorText) {
+				t.Fatalf("expected error %q, got %q", tc.errorText, err.Error())
+			}
+		})
+	}
+}
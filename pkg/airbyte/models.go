@@ -4,6 +4,12 @@ type Workspace struct {
 	ID             string
 	OrganizationId string
 	Name           string
+	// The details below come from the private workspace API, so they are only known for workspaces of accessible
+	// organizations.
+	Slug               string
+	DefaultGeography   string
+	Tombstone          bool
+	WebhookConfigCount int
 }
 
 // ------------------------------------------------------------------------------------------------
@@ -33,6 +39,16 @@ type Organization struct {
 	Email string `json:"email"`
 }
 
+// InstanceAdmin is the permission type of instance administrators of self-managed deployments.
+// It outranks every organization and workspace permission.
+const InstanceAdmin = "instance_admin"
+
+// Scopes used by the permissions and user invitations APIs.
+const (
+	PermissionScopeOrganization = "organization"
+	PermissionScopeWorkspace    = "workspace"
+)
+
 type Permission struct {
 	ID             string `json:"permissionId"`
 	PermissionType string `json:"permissionType"`
@@ -41,6 +57,19 @@ type Permission struct {
 	Scope          string `json:"scope"`
 }
 
+type ApplicationListResponse struct {
+	Applications []*Application `json:"applications"`
+}
+
+type Application struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	// CreatedAt is the creation time in seconds since the Unix epoch.
+	CreatedAt int64 `json:"createdAt"`
+}
+
 // APIResponse is a generic wrapper for public API responses.
 type APIResponse[T any] struct {
 	Data     T      `json:"data"`
@@ -80,6 +109,46 @@ type WorkspaceResponse struct {
 	} `json:"notifications"`
 }
 
+type ConnectionResponse struct {
+	ID            string             `json:"connectionId"`
+	Name          string             `json:"name"`
+	SourceID      string             `json:"sourceId"`
+	DestinationID string             `json:"destinationId"`
+	WorkspaceID   string             `json:"workspaceId"`
+	Status        string             `json:"status"`
+	Schedule      ConnectionSchedule `json:"schedule"`
+	DataResidency string             `json:"dataResidency"`
+	// CreatedAt is the creation time in seconds since the Unix epoch.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// ConnectionSchedule represents when a connection syncs.
+type ConnectionSchedule struct {
+	ScheduleType   string `json:"scheduleType"`
+	CronExpression string `json:"cronExpression"`
+	BasicTiming    string `json:"basicTiming"`
+}
+
+type SourceResponse struct {
+	ID           string `json:"sourceId"`
+	Name         string `json:"name"`
+	SourceType   string `json:"sourceType"`
+	DefinitionID string `json:"definitionId"`
+	WorkspaceID  string `json:"workspaceId"`
+	// CreatedAt is the creation time in seconds since the Unix epoch.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+type DestinationResponse struct {
+	ID              string `json:"destinationId"`
+	Name            string `json:"name"`
+	DestinationType string `json:"destinationType"`
+	DefinitionID    string `json:"definitionId"`
+	WorkspaceID     string `json:"workspaceId"`
+	// CreatedAt is the creation time in seconds since the Unix epoch.
+	CreatedAt int64 `json:"createdAt"`
+}
+
 // NotificationSetting represents the enabled/disabled state of a notification channel.
 type NotificationSetting struct {
 	Enabled bool `json:"enabled"`
@@ -150,3 +219,90 @@ type PermissionRead struct {
 	WorkspaceID    string `json:"workspaceId,omitempty"`
 	OrganizationID string `json:"organizationId,omitempty"`
 }
+
+type OrganizationUserReadListResponse struct {
+	Users []OrganizationUserReadResponse `json:"users"`
+}
+
+type OrganizationUserReadResponse struct {
+	UserID         string `json:"userId"`
+	Email          string `json:"email"`
+	Name           string `json:"name"`
+	OrganizationID string `json:"organizationId"`
+	PermissionID   string `json:"permissionId"`
+	PermissionType string `json:"permissionType"`
+}
+
+// Statuses of Airbyte users.
+const (
+	UserStatusInvited    = "invited"
+	UserStatusRegistered = "registered"
+	UserStatusDisabled   = "disabled"
+)
+
+type UserReadResponse struct {
+	UserID             string `json:"userId"`
+	Name               string `json:"name"`
+	Email              string `json:"email"`
+	AuthUserID         string `json:"authUserId"`
+	AuthProvider       string `json:"authProvider"`
+	Status             string `json:"status"`
+	CompanyName        string `json:"companyName"`
+	DefaultWorkspaceID string `json:"defaultWorkspaceId"`
+	// CreatedAt and LastLoginAt are times in seconds since the Unix epoch, only set when the deployment reports them.
+	CreatedAt   int64 `json:"createdAt"`
+	LastLoginAt int64 `json:"lastLoginAt"`
+}
+
+type InstanceAdminReadListResponse struct {
+	Users []InstanceAdminReadResponse `json:"users"`
+}
+
+type InstanceAdminReadResponse struct {
+	UserID       string `json:"userId"`
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	PermissionID string `json:"permissionId"`
+}
+
+type InstanceConfigurationResponse struct {
+	Edition                  string `json:"edition"`
+	Version                  string `json:"version"`
+	LicenseType              string `json:"licenseType"`
+	AirbyteURL               string `json:"airbyteUrl"`
+	InitialSetupComplete     bool   `json:"initialSetupComplete"`
+	DefaultUserID            string `json:"defaultUserId"`
+	DefaultOrganizationID    string `json:"defaultOrganizationId"`
+	DefaultOrganizationEmail string `json:"defaultOrganizationEmail"`
+	TrackingStrategy         string `json:"trackingStrategy"`
+
+	Auth *InstanceAuthConfiguration `json:"auth,omitempty"`
+}
+
+type InstanceAuthConfiguration struct {
+	Mode string `json:"mode"`
+}
+
+type HealthCheckResponse struct {
+	Available bool `json:"available"`
+}
+
+type UserInvitationReadResponse struct {
+	ID             string `json:"id"`
+	InviteCode     string `json:"inviteCode"`
+	InviterUserID  string `json:"inviterUserId"`
+	InvitedEmail   string `json:"invitedEmail"`
+	ScopeID        string `json:"scopeId"`
+	ScopeType      string `json:"scopeType"`
+	ScopeName      string `json:"scopeName"`
+	PermissionType string `json:"permissionType"`
+	Status         string `json:"status"`
+	// CreatedAt and ExpiresAt are times in seconds since the Unix epoch.
+	CreatedAt int64 `json:"createdAt"`
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+type UserInvitationCreateResponse struct {
+	InviteCode    string `json:"inviteCode"`
+	DirectlyAdded bool   `json:"directlyAdded"`
+}
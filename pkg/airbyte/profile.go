@@ -0,0 +1,127 @@
+package airbyte
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Deployment profiles locating the APIs of the common Airbyte layouts.
+const (
+	// DeploymentProfileSelfManaged serves the public API under /api/public/v1 and the config API under /api/v1 of the
+	// deployment hostname.
+	DeploymentProfileSelfManaged = "self-managed"
+	// DeploymentProfileCloud serves the public API at api.airbyte.com and the config API under cloud.airbyte.com.
+	DeploymentProfileCloud = "cloud"
+)
+
+const (
+	cloudWebURL       = "https://cloud.airbyte.com"
+	cloudPublicAPIURL = "https://api.airbyte.com/v1"
+	cloudConfigAPIURL = "https://cloud.airbyte.com/api/v1"
+)
+
+// Endpoints locates the web app, the APIs and the token endpoint of an Airbyte deployment.
+type Endpoints struct {
+	// WebURL is the root of the web app, which the links to workspaces and the Keycloak of self-managed deployments
+	// are resolved against.
+	WebURL string
+	// PublicAPIURL is the root of the public API, e.g. https://api.airbyte.com/v1.
+	PublicAPIURL string
+	// ConfigAPIURL is the root of the private config API the web app uses, e.g. https://airbyte.example.com/api/v1.
+	ConfigAPIURL string
+	// TokenURL is the endpoint exchanging application credentials for access tokens.
+	TokenURL string
+}
+
+// ResolveEndpoints returns the endpoints of a deployment of the profile at hostname.
+// The fields set in overrides take precedence over those of the profile, relative URLs are resolved against the web
+// app. The hostname may be empty for Airbyte Cloud.
+func ResolveEndpoints(profile string, hostname string, overrides Endpoints) (Endpoints, error) {
+	var endpoints Endpoints
+	switch profile {
+	case "", DeploymentProfileSelfManaged:
+		if hostname == "" && overrides.WebURL == "" {
+			return Endpoints{}, fmt.Errorf("airbyte: a hostname is required for self-managed deployments")
+		}
+		endpoints = Endpoints{
+			WebURL:       hostname,
+			PublicAPIURL: "/api/public/v1",
+			ConfigAPIURL: "/api/v1",
+			TokenURL:     "/api/v1/applications/token",
+		}
+	case DeploymentProfileCloud:
+		endpoints = Endpoints{
+			WebURL:       cloudWebURL,
+			PublicAPIURL: cloudPublicAPIURL,
+			ConfigAPIURL: cloudConfigAPIURL,
+			TokenURL:     cloudPublicAPIURL + "/applications/token",
+		}
+		if hostname != "" {
+			endpoints.WebURL = hostname
+		}
+	default:
+		return Endpoints{}, fmt.Errorf("airbyte: unsupported deployment profile %q", profile)
+	}
+
+	if overrides.WebURL != "" {
+		endpoints.WebURL = overrides.WebURL
+	}
+	if overrides.PublicAPIURL != "" {
+		endpoints.PublicAPIURL = overrides.PublicAPIURL
+	}
+	if overrides.ConfigAPIURL != "" {
+		endpoints.ConfigAPIURL = overrides.ConfigAPIURL
+	}
+	if overrides.TokenURL != "" {
+		endpoints.TokenURL = overrides.TokenURL
+	}
+
+	webURL, err := url.Parse(endpoints.WebURL)
+	if err != nil {
+		return Endpoints{}, fmt.Errorf("airbyte: invalid web app URL %q: %w", endpoints.WebURL, err)
+	}
+	// A hostname without a scheme, e.g. airbyte.example.com, would resolve every endpoint to a relative path.
+	if !webURL.IsAbs() || webURL.Host == "" {
+		return Endpoints{}, fmt.Errorf("airbyte: hostname %q must be an absolute URL such as https://airbyte.example.com", endpoints.WebURL)
+	}
+
+	for _, u := range []*string{&endpoints.PublicAPIURL, &endpoints.ConfigAPIURL, &endpoints.TokenURL} {
+		ref, err := url.Parse(*u)
+		if err != nil {
+			return Endpoints{}, fmt.Errorf("airbyte: invalid endpoint URL %q: %w", *u, err)
+		}
+		*u = strings.TrimSuffix(webURL.ResolveReference(ref).String(), "/")
+	}
+
+	return endpoints, nil
+}
+
+// endpointURLs are the parsed endpoints of the deployment the client connects to.
+type endpointURLs struct {
+	web       *url.URL
+	publicAPI *url.URL
+	configAPI *url.URL
+	token     *url.URL
+}
+
+func parseEndpoints(endpoints Endpoints) (*endpointURLs, error) {
+	urls := &endpointURLs{}
+	for _, e := range []struct {
+		raw    string
+		parsed **url.URL
+	}{
+		{endpoints.WebURL, &urls.web},
+		{endpoints.PublicAPIURL, &urls.publicAPI},
+		{endpoints.ConfigAPIURL, &urls.configAPI},
+		{endpoints.TokenURL, &urls.token},
+	} {
+		u, err := url.Parse(e.raw)
+		if err != nil {
+			return nil, err
+		}
+		*e.parsed = u
+	}
+
+	return urls, nil
+}
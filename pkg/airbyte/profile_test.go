@@ -0,0 +1,125 @@
+package airbyte
+
+import (
+	"testing"
+)
+
+func TestResolveEndpoints(t *testing.T) {
+	testCases := []struct {
+		message   string
+		profile   string
+		hostname  string
+		overrides Endpoints
+		expected  Endpoints
+		isValid   bool
+	}{
+		{
+			message:  "self-managed",
+			profile:  DeploymentProfileSelfManaged,
+			hostname: "https://airbyte.example.com",
+			expected: Endpoints{
+				WebURL:       "https://airbyte.example.com",
+				PublicAPIURL: "https://airbyte.example.com/api/public/v1",
+				ConfigAPIURL: "https://airbyte.example.com/api/v1",
+				TokenURL:     "https://airbyte.example.com/api/v1/applications/token",
+			},
+			isValid: true,
+		},
+		{
+			message:  "default profile",
+			hostname: "https://airbyte.example.com/",
+			expected: Endpoints{
+				WebURL:       "https://airbyte.example.com/",
+				PublicAPIURL: "https://airbyte.example.com/api/public/v1",
+				ConfigAPIURL: "https://airbyte.example.com/api/v1",
+				TokenURL:     "https://airbyte.example.com/api/v1/applications/token",
+			},
+			isValid: true,
+		},
+		{
+			message: "cloud",
+			profile: DeploymentProfileCloud,
+			expected: Endpoints{
+				WebURL:       "https://cloud.airbyte.com",
+				PublicAPIURL: "https://api.airbyte.com/v1",
+				ConfigAPIURL: "https://cloud.airbyte.com/api/v1",
+				TokenURL:     "https://api.airbyte.com/v1/applications/token",
+			},
+			isValid: true,
+		},
+		{
+			message:  "overrides",
+			profile:  DeploymentProfileSelfManaged,
+			hostname: "https://airbyte.example.com",
+			overrides: Endpoints{
+				PublicAPIURL: "/public/v1/",
+				TokenURL:     "https://auth.example.com/token",
+			},
+			expected: Endpoints{
+				WebURL:       "https://airbyte.example.com",
+				PublicAPIURL: "https://airbyte.example.com/public/v1",
+				ConfigAPIURL: "https://airbyte.example.com/api/v1",
+				TokenURL:     "https://auth.example.com/token",
+			},
+			isValid: true,
+		},
+		{
+			message: "self-managed without hostname",
+			profile: DeploymentProfileSelfManaged,
+			isValid: false,
+		},
+		{
+			message:  "schemeless hostname",
+			profile:  DeploymentProfileSelfManaged,
+			hostname: "airbyte.eu.example.com",
+			isValid:  false,
+		},
+		{
+			message:  "hostname with port but no scheme",
+			profile:  DeploymentProfileCloud,
+			hostname: "localhost:8000",
+			isValid:  false,
+		},
+		{
+			message:  "unsupported profile",
+			profile:  "serverless",
+			hostname: "https://airbyte.example.com",
+			isValid:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			endpoints, err := ResolveEndpoints(tc.profile, tc.hostname, tc.overrides)
+			if (err == nil) != tc.isValid {
+				t.Fatalf("expected valid %t, got error %v", tc.isValid, err)
+			}
+			if endpoints != tc.expected {
+				t.Fatalf("expected %+v, got %+v", tc.expected, endpoints)
+			}
+		})
+	}
+}
+
+func TestBuildResourceURL(t *testing.T) {
+	endpoints, err := ResolveEndpoints(DeploymentProfileCloud, "", Endpoints{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	urls, err := parseEndpoints(endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{urls: urls}
+	u := c.buildResourceURL(c.urls.publicAPI, getWorkspacePath, map[string]string{"workspaceId": "123"}, map[string]string{"limit": "10"})
+	if expected := "https://api.airbyte.com/v1/workspaces/123?limit=10"; u.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, u.String())
+	}
+
+	u = c.buildResourceURL(c.urls.configAPI, getUserPath, nil, nil)
+	if expected := "https://cloud.airbyte.com/api/v1/users/get"; u.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, u.String())
+	}
+}
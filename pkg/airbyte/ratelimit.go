@@ -0,0 +1,156 @@
+package airbyte
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/ratelimit"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"google.golang.org/protobuf/proto"
+)
+
+// RetryPolicy controls how requests rejected by Airbyte rate limiting (429) or temporary unavailability (503)
+// are retried.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a single request.
+	MaxRetries int
+	// MaxWait is the total time a single request may spend waiting between retries.
+	MaxWait time.Duration
+	// BaseDelay is the delay before the first retry when Airbyte doesn't send a Retry-After header.
+	// It doubles with each retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two retries when Airbyte doesn't send a Retry-After header.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used unless WithRetryPolicy is passed to NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	MaxWait:    2 * time.Minute,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+// isRetryableStatus reports whether a request with the method, rejected with the status code, may be retried later.
+//
+// A 429 means Airbyte didn't process the request, so any request is retried. A 503 may come from a proxy after the
+// backend already committed the request, so only idempotent requests are retried, e.g. never a POST creating an
+// application.
+func isRetryableStatus(method string, statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return isIdempotentMethod(method)
+	default:
+		return false
+	}
+}
+
+// isIdempotentMethod reports whether sending a request with the method several times has the same effect as once.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns how long to wait before the given retry (starting at 0).
+// The Retry-After delay sent by Airbyte is honored, otherwise the delay backs off exponentially.
+// Jitter is added in both cases so concurrent requests don't retry in lockstep.
+func (p RetryPolicy) retryDelay(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		// #nosec G404 -- jitter doesn't need a cryptographically secure source.
+		return retryAfter + time.Duration(rand.Int64N(int64(retryAfter)/10+1))
+	}
+
+	delay := p.BaseDelay << retry
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	// Equal jitter: wait between half and the full backoff delay.
+	// #nosec G404 -- jitter doesn't need a cryptographically secure source.
+	return delay/2 + time.Duration(rand.Int64N(int64(delay/2)+1))
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as an HTTP date.
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// sleepContext waits for the duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withRateLimitTracking records the rate limit state reported by a response on the client.
+// Responses without rate limit information leave the recorded state untouched.
+func (c *Client) withRateLimitTracking() uhttp.DoOption {
+	return func(resp *uhttp.WrapperResponse) error {
+		description, err := ratelimit.ExtractRateLimitData(resp.StatusCode, &resp.Header)
+		if err != nil || description == nil {
+			// Malformed rate limit headers must not fail the request.
+			return nil
+		}
+
+		if description.Limit == 0 && description.Status != v2.RateLimitDescription_STATUS_OVERLIMIT {
+			return nil
+		}
+
+		c.rateLimitMu.Lock()
+		c.rateLimit = description
+		c.rateLimitMu.Unlock()
+
+		return nil
+	}
+}
+
+// RateLimitDescription returns the most recent rate limit state reported by Airbyte, or nil if Airbyte hasn't
+// reported any. Airbyte rate limits are shared by all requests of an application, so the latest state applies to
+// the whole client.
+func (c *Client) RateLimitDescription() *v2.RateLimitDescription {
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+
+	if c.rateLimit == nil {
+		return nil
+	}
+
+	rateLimit, ok := proto.Clone(c.rateLimit).(*v2.RateLimitDescription)
+	if !ok {
+		return nil
+	}
+
+	return rateLimit
+}
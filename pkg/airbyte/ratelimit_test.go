@@ -0,0 +1,93 @@
+package airbyte
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableStatus(t *testing.T) {
+	testCases := []struct {
+		method     string
+		statusCode int
+		retryable  bool
+	}{
+		{http.MethodGet, http.StatusTooManyRequests, true},
+		{http.MethodPost, http.StatusTooManyRequests, true},
+		{http.MethodGet, http.StatusServiceUnavailable, true},
+		{http.MethodDelete, http.StatusServiceUnavailable, true},
+		{http.MethodPost, http.StatusServiceUnavailable, false},
+		{http.MethodPatch, http.StatusServiceUnavailable, false},
+		{http.MethodGet, http.StatusInternalServerError, false},
+	}
+
+	for _, tc := range testCases {
+		if retryable := isRetryableStatus(tc.method, tc.statusCode); retryable != tc.retryable {
+			t.Errorf("%s %d: expected retryable %t, got %t", tc.method, tc.statusCode, tc.retryable, retryable)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{
+		BaseDelay: time.Second,
+		MaxDelay:  10 * time.Second,
+	}
+
+	testCases := []struct {
+		message    string
+		retry      int
+		retryAfter time.Duration
+		min        time.Duration
+		max        time.Duration
+	}{
+		{"first retry", 0, 0, 500 * time.Millisecond, time.Second},
+		{"exponential backoff", 2, 0, 2 * time.Second, 4 * time.Second},
+		{"capped backoff", 5, 0, 5 * time.Second, 10 * time.Second},
+		{"shift overflow is capped", 70, 0, 5 * time.Second, 10 * time.Second},
+		{"retry after honored", 0, 20 * time.Second, 20 * time.Second, 22 * time.Second},
+		{"retry after above max delay", 3, time.Minute, time.Minute, 66 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			for range 100 {
+				delay := policy.retryDelay(tc.retry, tc.retryAfter)
+				if delay < tc.min || delay > tc.max {
+					t.Fatalf("expected a delay in [%s, %s], got %s", tc.min, tc.max, delay)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		message string
+		value   string
+		delay   time.Duration
+	}{
+		{"missing", "", 0},
+		{"seconds", "30", 30 * time.Second},
+		{"zero seconds", "0", 0},
+		{"negative seconds", "-5", 0},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"http date in the past", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"malformed", "soon", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			header := http.Header{}
+			if tc.value != "" {
+				header.Set("Retry-After", tc.value)
+			}
+
+			if delay := parseRetryAfter(header, now); delay != tc.delay {
+				t.Fatalf("expected %s, got %s", tc.delay, delay)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package airbyte
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// tokenExpiryBuffer is how long before its expiry a token stops being handed out.
+	tokenExpiryBuffer = 30 * time.Second
+	// tokenRefreshAhead is how long before its expiry a token in use is refreshed in the background.
+	tokenRefreshAhead = 60 * time.Second
+)
+
+// tokenFetchFunc obtains a new access token and its expiry.
+type tokenFetchFunc func(ctx context.Context) (string, time.Time, error)
+
+// tokenRefresh is a token fetch in flight that concurrent callers wait on.
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// tokenSource hands out access tokens to concurrent requests.
+//
+// Access token lifetimes vary by Airbyte deployment type/version:
+// • Open Source/Cloud: 3 minutes
+// • Enterprise: 24 hours
+//
+// The token is refreshed when:
+// • The token is not set (first time access)
+// • The token is expired or expires in the next 30 seconds
+// • The token was rejected by Airbyte before its expiry (see invalidate)
+//
+// Tokens without an expiry, e.g. pre-issued bearer tokens, are only fetched again once rejected.
+//
+// Only one refresh runs at a time, callers that need a token while a refresh is in flight wait for its result.
+// Tokens that are in use are also refreshed in the background shortly before they expire, so long syncs don't
+// stall on a refresh every few minutes.
+//
+// Reference: https://reference.airbyte.com/reference/authentication
+type tokenSource struct {
+	// ctx is the context background refreshes run with.
+	ctx   context.Context
+	fetch tokenFetchFunc
+	now   func() time.Time
+
+	mu       sync.Mutex
+	token    string
+	expiry   time.Time
+	used     bool
+	inflight *tokenRefresh
+	timer    *time.Timer
+}
+
+func newTokenSource(ctx context.Context, fetch tokenFetchFunc) *tokenSource {
+	return &tokenSource{
+		ctx:   context.WithoutCancel(ctx),
+		fetch: fetch,
+		now:   time.Now,
+	}
+}
+
+// Token returns a valid access token, fetching a new one if needed.
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	if s.validLocked() {
+		s.used = true
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	refresh := s.startRefreshLocked(ctx)
+	s.mu.Unlock()
+
+	select {
+	case <-refresh.done:
+		if refresh.err != nil {
+			return "", refresh.err
+		}
+		s.mu.Lock()
+		s.used = true
+		s.mu.Unlock()
+		return refresh.token, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// invalidate discards the token if it is still the current one, so the next call to Token fetches a new one.
+// It is used when Airbyte rejects a token before its expiry, e.g. because it was revoked.
+func (s *tokenSource) invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+		s.expiry = time.Time{}
+	}
+}
+
+// validLocked reports whether the current token can be handed out. Tokens with a zero expiry never expire.
+func (s *tokenSource) validLocked() bool {
+	return s.token != "" && (s.expiry.IsZero() || s.now().Add(tokenExpiryBuffer).Before(s.expiry))
+}
+
+// startRefreshLocked returns the refresh in flight, starting a new one if there is none.
+// It must be called with s.mu held.
+func (s *tokenSource) startRefreshLocked(ctx context.Context) *tokenRefresh {
+	if s.inflight != nil {
+		return s.inflight
+	}
+
+	refresh := &tokenRefresh{done: make(chan struct{})}
+	s.inflight = refresh
+
+	// The fetch must not be canceled by the caller that happened to start it, other callers wait on it as well.
+	go s.runRefresh(context.WithoutCancel(ctx), refresh)
+
+	return refresh
+}
+
+func (s *tokenSource) runRefresh(ctx context.Context, refresh *tokenRefresh) {
+	token, expiry, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inflight = nil
+	refresh.token = token
+	refresh.err = err
+	close(refresh.done)
+
+	if err != nil {
+		return
+	}
+
+	s.token = token
+	s.expiry = expiry
+	s.used = false
+	s.scheduleRefreshLocked()
+}
+
+// scheduleRefreshLocked arms the background refresh of the current token.
+// It must be called with s.mu held.
+func (s *tokenSource) scheduleRefreshLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+
+	delay := s.expiry.Sub(s.now()) - tokenRefreshAhead
+	if s.expiry.IsZero() || delay <= 0 {
+		return
+	}
+
+	s.timer = time.AfterFunc(delay, s.refreshInBackground)
+}
+
+// refreshInBackground refreshes the token ahead of its expiry if it has been used since it was issued.
+// Idle tokens are left to expire, they are fetched again on demand.
+func (s *tokenSource) refreshInBackground() {
+	s.mu.Lock()
+	if !s.used || s.inflight != nil {
+		s.mu.Unlock()
+		return
+	}
+	refresh := s.startRefreshLocked(s.ctx)
+	s.mu.Unlock()
+
+	<-refresh.done
+	if refresh.err != nil {
+		ctxzap.Extract(s.ctx).Warn("airbyte: background access token refresh failed", zap.Error(refresh.err))
+	}
+}
@@ -0,0 +1,139 @@
+package airbyte
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenSourceSingleFlight(t *testing.T) {
+	ctx := context.Background()
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	source := newTokenSource(ctx, func(ctx context.Context) (string, time.Time, error) {
+		n := fetches.Add(1)
+		<-release
+		return fmt.Sprintf("token-%d", n), time.Now().Add(time.Hour), nil
+	})
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	errs := make([]error, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[i], errs[i] = source.Token(ctx)
+		}()
+	}
+
+	// Give every goroutine a chance to wait on the fetch in flight before it completes.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("expected a single fetch, got %d", got)
+	}
+
+	for i := range tokens {
+		if errs[i] != nil {
+			t.Fatalf("unexpected error: %v", errs[i])
+		}
+		if tokens[i] != "token-1" {
+			t.Fatalf("expected token-1, got %s", tokens[i])
+		}
+	}
+}
+
+func TestTokenSourceRefreshesExpiringToken(t *testing.T) {
+	ctx := context.Background()
+
+	var fetches atomic.Int32
+	source := newTokenSource(ctx, func(ctx context.Context) (string, time.Time, error) {
+		n := fetches.Add(1)
+		// Tokens expire within the expiry buffer, so every call needs a new one.
+		return fmt.Sprintf("token-%d", n), time.Now().Add(tokenExpiryBuffer / 2), nil
+	})
+
+	first, err := source.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := source.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first == second {
+		t.Fatalf("expected an expiring token to be refreshed, got %s twice", first)
+	}
+}
+
+func TestTokenSourceInvalidate(t *testing.T) {
+	ctx := context.Background()
+
+	var fetches atomic.Int32
+	source := newTokenSource(ctx, func(ctx context.Context) (string, time.Time, error) {
+		n := fetches.Add(1)
+		return fmt.Sprintf("token-%d", n), time.Now().Add(time.Hour), nil
+	})
+
+	first, err := source.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Invalidating a token that is no longer current must not discard the current one.
+	source.invalidate("stale-token")
+	if token, _ := source.Token(ctx); token != first {
+		t.Fatalf("expected %s to be kept, got %s", first, token)
+	}
+
+	source.invalidate(first)
+	second, err := source.Token(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if second == first {
+		t.Fatalf("expected a new token after invalidation, got %s", second)
+	}
+}
+
+func TestTokenSourceFetchError(t *testing.T) {
+	ctx := context.Background()
+
+	source := newTokenSource(ctx, func(ctx context.Context) (string, time.Time, error) {
+		return "", time.Time{}, fmt.Errorf("invalid client credentials")
+	})
+
+	if _, err := source.Token(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestTokenSourceTokenWithoutExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	var fetches atomic.Int32
+	source := newTokenSource(ctx, func(ctx context.Context) (string, time.Time, error) {
+		n := fetches.Add(1)
+		return fmt.Sprintf("token-%d", n), time.Time{}, nil
+	})
+
+	for range 3 {
+		if _, err := source.Token(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("expected a token without expiry to be fetched once, got %d fetches", got)
+	}
+}
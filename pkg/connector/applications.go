@@ -0,0 +1,185 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+type applicationBuilder struct {
+	resourceType *v2.ResourceType
+	client       *airbyte.Client
+}
+
+func (o *applicationBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return applicationResourceType
+}
+
+// Create a new connector resource for an Airbyte application.
+// The application is linked to its owning user, since the application acts with that user's permissions.
+func applicationResource(application *airbyte.Application, ownerID string) (*v2.Resource, error) {
+	var secretTraitOptions []rs.SecretTraitOption
+
+	if application.CreatedAt > 0 {
+		secretTraitOptions = append(secretTraitOptions, rs.WithSecretCreatedAt(time.Unix(application.CreatedAt, 0)))
+	}
+
+	if ownerID != "" {
+		ownerResourceID := &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     ownerID,
+		}
+		secretTraitOptions = append(
+			secretTraitOptions,
+			rs.WithSecretCreatedByID(ownerResourceID),
+			rs.WithSecretIdentityID(ownerResourceID),
+		)
+	}
+
+	resource, err := rs.NewSecretResource(
+		application.Name,
+		applicationResourceType,
+		application.ID,
+		secretTraitOptions,
+		rs.WithExternalID(&v2.ExternalId{
+			Id:          application.ClientID,
+			Description: "Airbyte application client ID",
+		}),
+		rs.WithDescription(fmt.Sprintf("Airbyte application with client ID %s", application.ClientID)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns the applications owned by the user the connector is authenticated as.
+// The Airbyte applications API only exposes the applications of the calling user.
+func (o *applicationBuilder) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	applications, err := o.client.ListApplications(ctx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list applications: %w", err)
+	}
+
+	ownerID, err := o.client.GetCurrentUserID(ctx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to get the owner of the applications: %w", err)
+	}
+
+	resources := make([]*v2.Resource, 0, len(applications))
+	for _, application := range applications {
+		resource, err := applicationResource(application, ownerID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource for application %s: %w", application.Name, err)
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, "", rateLimitAnnotations(o.client), nil
+}
+
+// Entitlements always returns an empty slice for applications.
+func (o *applicationBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for applications since they don't have any entitlements.
+func (o *applicationBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Rotate replaces the client secret of an application.
+// Airbyte doesn't support regenerating the secret of an existing application, so a new application with the same
+// name is created and the old one is deleted. The new application is deleted again if the old one can't be removed,
+// so a failed rotation never leaves two valid credentials behind.
+func (o *applicationBuilder) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if resourceId.ResourceType != applicationResourceType.Id {
+		return nil, nil, fmt.Errorf("airbyte-connector: unsupported resource type %s", resourceId.ResourceType)
+	}
+
+	if credentialOptions.GetRandomPassword() == nil {
+		return nil, nil, fmt.Errorf("airbyte-connector: application secrets can only be rotated with the random password credential option")
+	}
+
+	application, err := o.client.GetApplication(ctx, resourceId.Resource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("airbyte-connector: failed to get application %s: %w", resourceId.Resource, err)
+	}
+
+	// Rotating the credential the connector authenticates with would revoke its own access mid-operation.
+	if application.ClientID == o.client.ClientID() {
+		return nil, nil, fmt.Errorf("airbyte-connector: cannot rotate application %s because the connector authenticates with it", application.ID)
+	}
+
+	newApplication, err := o.client.CreateApplication(ctx, application.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("airbyte-connector: failed to create replacement for application %s: %w", application.ID, err)
+	}
+
+	err = o.client.DeleteApplication(ctx, application.ID)
+	if err != nil {
+		rollbackErr := o.client.DeleteApplication(ctx, newApplication.ID)
+		if rollbackErr != nil {
+			l.Error(
+				"airbyte-connector: failed to delete replacement application after failed rotation",
+				zap.String("application_id", newApplication.ID),
+				zap.Error(rollbackErr),
+			)
+		}
+
+		return nil, nil, fmt.Errorf("airbyte-connector: failed to delete application %s: %w", application.ID, err)
+	}
+
+	l.Debug(
+		"airbyte-connector: application rotated",
+		zap.String("old_application_id", application.ID),
+		zap.String("new_application_id", newApplication.ID),
+	)
+
+	return []*v2.PlaintextData{
+		{
+			Name:        "client_id",
+			Description: "Airbyte application client ID",
+			Bytes:       []byte(newApplication.ClientID),
+		},
+		{
+			Name:        "client_secret",
+			Description: "Airbyte application client secret",
+			Bytes:       []byte(newApplication.ClientSecret),
+		},
+	}, nil, nil
+}
+
+// RotateCapabilityDetails advertises that application secrets are rotated as random secrets generated by Airbyte.
+func (o *applicationBuilder) RotateCapabilityDetails(_ context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+	}, nil, nil
+}
+
+func newApplicationBuilder(client *airbyte.Client) *applicationBuilder {
+	return &applicationBuilder{
+		resourceType: applicationResourceType,
+		client:       client,
+	}
+}
@@ -0,0 +1,105 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+func TestApplicationRotate(t *testing.T) {
+	testCases := []struct {
+		message      string
+		deleteStatus int
+		calls        []string
+		rotated      bool
+	}{
+		{
+			message:      "old application deleted",
+			deleteStatus: http.StatusNoContent,
+			calls: []string{
+				"GET /api/public/v1/applications/application-1",
+				"POST /api/public/v1/applications",
+				"DELETE /api/public/v1/applications/application-1",
+			},
+			rotated: true,
+		},
+		{
+			message:      "old application not deleted",
+			deleteStatus: http.StatusInternalServerError,
+			calls: []string{
+				"GET /api/public/v1/applications/application-1",
+				"POST /api/public/v1/applications",
+				"DELETE /api/public/v1/applications/application-1",
+				"DELETE /api/public/v1/applications/application-2",
+			},
+			rotated: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			ctx := context.Background()
+
+			var mu sync.Mutex
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				mu.Unlock()
+
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodGet:
+					_ = json.NewEncoder(w).Encode(airbyte.Application{ID: "application-1", Name: "etl", ClientID: "client-1"})
+				case r.Method == http.MethodPost:
+					_ = json.NewEncoder(w).Encode(airbyte.Application{ID: "application-2", Name: "etl", ClientID: "client-2", ClientSecret: "secret-2"})
+				case strings.HasSuffix(r.URL.Path, "/application-1"):
+					w.WriteHeader(tc.deleteStatus)
+				default:
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+
+			client, err := airbyte.NewClient(ctx, server.URL, airbyte.NoAuth())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			builder := newApplicationBuilder(client)
+			secrets, _, err := builder.Rotate(
+				ctx,
+				&v2.ResourceId{ResourceType: applicationResourceType.Id, Resource: "application-1"},
+				&v2.CredentialOptions{Options: &v2.CredentialOptions_RandomPassword_{RandomPassword: &v2.CredentialOptions_RandomPassword{}}},
+			)
+
+			if strings.Join(calls, ", ") != strings.Join(tc.calls, ", ") {
+				t.Fatalf("expected calls %v, got %v", tc.calls, calls)
+			}
+
+			if !tc.rotated {
+				if err == nil {
+					t.Fatal("expected the failed deletion of the old application to be reported")
+				}
+				if len(secrets) != 0 {
+					t.Fatal("expected no credential to be returned")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(secrets) != 2 || string(secrets[0].Bytes) != "client-2" || string(secrets[1].Bytes) != "secret-2" {
+				t.Fatalf("expected the credentials of the new application, got %v", secrets)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Capabilities of a deployment the connector depends on beyond the public API.
+const (
+	// capabilityInstanceConfiguration is the instance configuration endpoint, which reports the edition and auth mode.
+	capabilityInstanceConfiguration = "instance_configuration"
+	// capabilityWorkspacesByOrganization is the private endpoint listing the workspaces of an organization, which
+	// parents workspaces to their organization.
+	capabilityWorkspacesByOrganization = "workspaces_by_organization"
+	// capabilityWorkspaceAccessInfo is the private endpoint listing the users with access to a workspace, which
+	// workspace roles and users without organization role are synced from.
+	capabilityWorkspaceAccessInfo = "workspace_access_info"
+	// capabilityUserDetails is the private endpoint reading a user, which the status, authentication provider and
+	// timestamps of users are synced from.
+	capabilityUserDetails = "user_details"
+)
+
+// capabilities records what a deployment supports, as detected by probing it once at startup.
+//
+// Until the deployment is probed every capability is assumed to be available, so builders behave the same as when
+// the probe found everything. Builders check the capabilities they depend on and skip what a deployment doesn't
+// support instead of failing the sync.
+type capabilities struct {
+	probeOnce sync.Once
+	probeErr  error
+
+	mu       sync.RWMutex
+	healthy  bool
+	version  string
+	edition  string
+	authMode string
+	// missing maps the unavailable capabilities to why they are unavailable.
+	missing map[string]string
+}
+
+func newCapabilities() *capabilities {
+	return &capabilities{
+		missing: make(map[string]string),
+	}
+}
+
+// has returns whether the deployment supports the capability.
+func (c *capabilities) has(capability string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, missing := c.missing[capability]
+	return !missing
+}
+
+func (c *capabilities) setMissing(capability string, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.missing[capability] = reason
+}
+
+// probe detects the capabilities of the deployment. Only the first call probes, later calls return its result.
+func (c *capabilities) probe(ctx context.Context, client *airbyte.Client, orgs []*airbyte.Organization) error {
+	c.probeOnce.Do(func() {
+		c.probeErr = c.probeDeployment(ctx, client, orgs)
+	})
+
+	return c.probeErr
+}
+
+func (c *capabilities) probeDeployment(ctx context.Context, client *airbyte.Client, orgs []*airbyte.Organization) error {
+	l := ctxzap.Extract(ctx)
+
+	health, err := client.GetHealth(ctx)
+	if err != nil && !isUnsupported(err) {
+		return fmt.Errorf("airbyte-connector: failed to check the health of the deployment: %w", err)
+	}
+
+	c.mu.Lock()
+	c.healthy = health != nil && health.Available
+	c.mu.Unlock()
+
+	config, err := client.GetInstanceConfiguration(ctx)
+	switch {
+	case err == nil:
+		c.mu.Lock()
+		c.version = config.Version
+		c.edition = config.Edition
+		if config.Auth != nil {
+			c.authMode = config.Auth.Mode
+		}
+		c.mu.Unlock()
+	case isUnsupported(err):
+		c.setMissing(capabilityInstanceConfiguration, err.Error())
+	default:
+		return fmt.Errorf("airbyte-connector: failed to get the instance configuration: %w", err)
+	}
+
+	err = c.probeWorkspacesByOrganization(ctx, client, orgs)
+	if err != nil {
+		return err
+	}
+
+	err = c.probeWorkspaceAccessInfo(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	err = c.probeUserDetails(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	l.Info(
+		"airbyte-connector: probed deployment capabilities",
+		zap.String("host", client.Host()),
+		zap.Bool("healthy", c.healthy),
+		zap.String("version", c.version),
+		zap.String("edition", c.edition),
+		zap.String("auth_mode", c.authMode),
+	)
+	for capability, reason := range c.missing {
+		l.Warn(
+			"airbyte-connector: deployment capability is unavailable, the parts of the sync depending on it are skipped",
+			zap.String("capability", capability),
+			zap.String("reason", reason),
+		)
+	}
+	c.mu.RUnlock()
+
+	return nil
+}
+
+// probeWorkspacesByOrganization lists a workspace of the first organization whose workspaces the application may
+// read. Organizations it isn't allowed into don't tell whether the endpoint exists, so the next one is tried.
+func (c *capabilities) probeWorkspacesByOrganization(ctx context.Context, client *airbyte.Client, orgs []*airbyte.Organization) error {
+	if len(orgs) == 0 {
+		return nil
+	}
+
+	var lastErr error
+	for _, org := range orgs {
+		_, _, err := client.ListWorkspacesByOrganization(ctx, org.ID, 1, 0)
+		switch {
+		case err == nil:
+			return nil
+		case isDenied(err):
+			lastErr = err
+		case isUnsupported(err):
+			c.setMissing(capabilityWorkspacesByOrganization, err.Error())
+			return nil
+		default:
+			return fmt.Errorf("airbyte-connector: failed to probe the workspaces of organization %s: %w", org.ID, err)
+		}
+	}
+
+	c.setMissing(capabilityWorkspacesByOrganization, lastErr.Error())
+	return nil
+}
+
+// probeWorkspaceAccessInfo lists the users of the first workspace whose users the application may read.
+func (c *capabilities) probeWorkspaceAccessInfo(ctx context.Context, client *airbyte.Client) error {
+	workspaces, _, err := client.ListAllWorkspaces(ctx, ResourcesPageSize, "")
+	if err != nil {
+		return fmt.Errorf("airbyte-connector: failed to list workspaces: %w", err)
+	}
+
+	if len(workspaces) == 0 {
+		return nil
+	}
+
+	var lastErr error
+	for _, workspace := range workspaces {
+		_, err := client.ListUsersWithAccessInfoByWorkspace(ctx, workspace.ID)
+		switch {
+		case err == nil:
+			return nil
+		case isDenied(err):
+			lastErr = err
+		case isUnsupported(err):
+			c.setMissing(capabilityWorkspaceAccessInfo, err.Error())
+			return nil
+		default:
+			return fmt.Errorf("airbyte-connector: failed to probe the users of workspace %s: %w", workspace.ID, err)
+		}
+	}
+
+	c.setMissing(capabilityWorkspaceAccessInfo, lastErr.Error())
+	return nil
+}
+
+// probeUserDetails reads the user the connector is authenticated as. The users are looked up one by one during the
+// sync, so an endpoint that isn't served is detected here instead of failing or being retried for every user.
+func (c *capabilities) probeUserDetails(ctx context.Context, client *airbyte.Client) error {
+	userID, err := client.GetCurrentUserID(ctx)
+	if err != nil {
+		// Without a user to read the endpoint stays assumed, the sync stops looking users up at the first refusal.
+		ctxzap.Extract(ctx).Debug("airbyte-connector: failed to get the current user to probe user details", zap.Error(err))
+		return nil
+	}
+
+	_, err = client.GetUser(ctx, userID)
+	switch {
+	case err == nil:
+		return nil
+	case isUnsupported(err):
+		c.setMissing(capabilityUserDetails, err.Error())
+		return nil
+	default:
+		return fmt.Errorf("airbyte-connector: failed to probe the details of user %s: %w", userID, err)
+	}
+}
+
+// isDenied returns whether the application isn't allowed to make a request the deployment supports.
+func isDenied(err error) bool {
+	return status.Code(err) == codes.PermissionDenied
+}
+
+// isUnsupported returns whether a request failed because the deployment doesn't serve the endpoint to the application,
+// e.g. private endpoints on Airbyte Cloud.
+func isUnsupported(err error) bool {
+	switch status.Code(err) {
+	case codes.NotFound, codes.Unimplemented, codes.PermissionDenied, codes.Unauthenticated:
+		return true
+	default:
+		return false
+	}
+}
+
+// skippedAnnotation reports a part of the sync that was skipped because the deployment lacks a capability.
+func skippedAnnotation(capability string, skipped string) *structpb.Struct {
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"skipped":            structpb.NewStringValue(skipped),
+			"missing_capability": structpb.NewStringValue(capability),
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+)
+
+func TestCapabilitiesProbe(t *testing.T) {
+	ctx := context.Background()
+
+	// The deployment serves the public API and its health, but none of the private endpoints the connector uses.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/health":
+			_ = json.NewEncoder(w).Encode(map[string]bool{"available": true})
+		case "/api/public/v1/workspaces":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]string{{"workspaceId": "workspace-1", "name": "Analytics"}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := airbyte.NewClient(ctx, server.URL, airbyte.NoAuth())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caps := newCapabilities()
+	if !caps.has(capabilityWorkspaceAccessInfo) {
+		t.Fatal("expected capabilities to be available before the probe")
+	}
+
+	err = caps.probe(ctx, client, []*airbyte.Organization{{ID: "org-1", Name: "Acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !caps.healthy {
+		t.Fatal("expected the deployment to be healthy")
+	}
+	for _, capability := range []string{
+		capabilityInstanceConfiguration,
+		capabilityWorkspacesByOrganization,
+		capabilityWorkspaceAccessInfo,
+		capabilityUserDetails,
+	} {
+		if caps.has(capability) {
+			t.Fatalf("expected %s to be unavailable", capability)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+type connectionBuilder struct {
+	resourceType *v2.ResourceType
+	client       *airbyte.Client
+}
+
+func (o *connectionBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return connectionResourceType
+}
+
+// Create a new connector resource for an Airbyte connection.
+// The profile records the source and destination the connection moves data between, and when it runs.
+func connectionResource(connection *airbyte.ConnectionResponse, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"connection_id":   connection.ID,
+		"name":            connection.Name,
+		"status":          connection.Status,
+		"source_id":       connection.SourceID,
+		"destination_id":  connection.DestinationID,
+		"workspace_id":    connection.WorkspaceID,
+		"schedule_type":   connection.Schedule.ScheduleType,
+		"cron_expression": connection.Schedule.CronExpression,
+		"basic_timing":    connection.Schedule.BasicTiming,
+		"data_residency":  connection.DataResidency,
+	}
+
+	if connection.CreatedAt > 0 {
+		profile["created_at"] = connection.CreatedAt
+	}
+
+	resource, err := rs.NewAppResource(
+		connection.Name,
+		connectionResourceType,
+		connection.ID,
+		[]rs.AppTraitOption{rs.WithAppProfile(profile)},
+		rs.WithParentResourceID(parentResourceID),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns the connections of the parent workspace.
+func (o *connectionBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	// pToken.Token is the offset for the current page
+	bag, offsetForCurrentPage, err := parsePageToken(pToken, &v2.ResourceId{ResourceType: connectionResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	connections, offsetForNextPage, err := o.client.ListConnectionsByWorkspace(ctx, parentResourceID.Resource, ResourcesPageSize, offsetForCurrentPage)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list connections under workspace %s: %w", parentResourceID.Resource, err)
+	}
+
+	next, err := bag.NextToken(offsetForNextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resources := make([]*v2.Resource, 0, len(connections))
+	for _, connection := range connections {
+		resource, err := connectionResource(connection, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource for connection %s: %w", connection.Name, err)
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, next, rateLimitAnnotations(o.client), nil
+}
+
+// Entitlements always returns an empty slice for connections.
+func (o *connectionBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for connections since they don't have any entitlements.
+func (o *connectionBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newConnectionBuilder(client *airbyte.Client) *connectionBuilder {
+	return &connectionBuilder{
+		resourceType: connectionResourceType,
+		client:       client,
+	}
+}
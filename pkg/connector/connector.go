@@ -2,6 +2,7 @@ package connector
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/conductorone/baton-airbyte/pkg/airbyte"
@@ -14,15 +15,93 @@ import (
 
 // Airbyte represents the Baton connector for Airbyte.
 type Airbyte struct {
-	client *airbyte.Client
+	// deployments are the Airbyte deployments synced by the connector.
+	deployments []*deployment
+	clientOpts  []airbyte.Option
+
+	// topLevelUnattributedWorkspaces lists workspaces whose organization can't be resolved as top-level resources
+	// instead of under the synthetic unattributed organization.
+	topLevelUnattributedWorkspaces bool
+
+	// skipTombstonedWorkspaces skips tombstoned workspaces instead of syncing them flagged in their profile.
+	skipTombstonedWorkspaces bool
+
+	// organizationFilter and workspaceFilter select the organizations and workspaces that are synced.
+	organizationFilter *ResourceFilter
+	workspaceFilter    *ResourceFilter
+}
+
+// Option configures the connector.
+type Option func(*Airbyte)
+
+// WithClientOptions configures the Airbyte API client used by the connector.
+func WithClientOptions(opts ...airbyte.Option) Option {
+	return func(a *Airbyte) {
+		a.clientOpts = append(a.clientOpts, opts...)
+	}
+}
+
+// WithTopLevelUnattributedWorkspaces lists workspaces whose organization can't be resolved as top-level resources
+// instead of under the synthetic unattributed organization.
+func WithTopLevelUnattributedWorkspaces(topLevel bool) Option {
+	return func(a *Airbyte) {
+		a.topLevelUnattributedWorkspaces = topLevel
+	}
+}
+
+// WithSkipTombstonedWorkspaces skips tombstoned workspaces instead of syncing them flagged in their profile.
+func WithSkipTombstonedWorkspaces(skip bool) Option {
+	return func(a *Airbyte) {
+		a.skipTombstonedWorkspaces = skip
+	}
+}
+
+// WithOrganizationFilter syncs only the organizations selected by the filter, along with their workspaces.
+func WithOrganizationFilter(filter *ResourceFilter) Option {
+	return func(a *Airbyte) {
+		a.organizationFilter = filter
+	}
+}
+
+// WithWorkspaceFilter syncs only the workspaces selected by the filter.
+func WithWorkspaceFilter(filter *ResourceFilter) Option {
+	return func(a *Airbyte) {
+		a.workspaceFilter = filter
+	}
 }
 
 // ResourceSyncers returns a list of syncers for different resource types.
+// When several deployments are synced, the syncers of every deployment are combined into one syncer per resource type.
 func (a *Airbyte) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
+	if len(a.deployments) == 1 && a.deployments[0].name == "" {
+		return a.deploymentResourceSyncers(a.deployments[0])
+	}
+
+	var syncers []connectorbuilder.ResourceSyncer
+	for _, d := range a.deployments {
+		for i, syncer := range a.deploymentResourceSyncers(d) {
+			if i == len(syncers) {
+				syncers = append(syncers, newMultiDeploymentSyncer(syncer))
+			}
+			syncers[i].(multiDeploymentResourceSyncer).addDeployment(d.name, syncer)
+		}
+	}
+
+	return syncers
+}
+
+// deploymentResourceSyncers returns the syncers of a deployment, in the same order for every deployment.
+func (a *Airbyte) deploymentResourceSyncers(d *deployment) []connectorbuilder.ResourceSyncer {
 	return []connectorbuilder.ResourceSyncer{
-		newOrgBuilder(a.client),
-		newUserBuilder(a.client),
-		newWorkspaceBuilder(a.client),
+		newInstanceBuilder(d.client),
+		newOrgBuilder(d.client, d.workspaceIndex, d.scope, d.capabilities, a.topLevelUnattributedWorkspaces),
+		newUserBuilder(d.client, d.workspaceIndex, d.scope, d.capabilities),
+		newInvitationBuilder(d.client),
+		newWorkspaceBuilder(d.client, d.workspaceIndex, d.scope, d.capabilities, a.topLevelUnattributedWorkspaces, a.skipTombstonedWorkspaces),
+		newApplicationBuilder(d.client),
+		newConnectionBuilder(d.client),
+		newSourceBuilder(d.client),
+		newDestinationBuilder(d.client),
 	}
 }
 
@@ -37,6 +116,47 @@ func (d *Airbyte) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	return &v2.ConnectorMetadata{
 		DisplayName: "Airbyte Baton Connector",
 		Description: "Connector syncing Airbyte organizations and users to Baton",
+		AccountCreationSchema: &v2.ConnectorAccountCreationSchema{
+			FieldMap: map[string]*v2.ConnectorAccountCreationSchema_Field{
+				"email": {
+					DisplayName: "Email",
+					Required:    true,
+					Description: "The email address the invitation is sent to.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Placeholder: "user@example.com",
+					Order:       1,
+				},
+				"organization_id": {
+					DisplayName: "Organization ID",
+					Required:    true,
+					Description: "The Airbyte organization the user is invited into.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Order: 2,
+				},
+				"workspace_id": {
+					DisplayName: "Workspace ID",
+					Required:    false,
+					Description: "An optional workspace of the organization the user is invited into instead of the whole organization.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Order: 3,
+				},
+				"permission_type": {
+					DisplayName: "Role",
+					Required:    false,
+					Description: "The initial role, e.g. organization_member or workspace_reader. Defaults to organization_member, or workspace_reader when a workspace is set.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Order: 4,
+				},
+			},
+		},
 	}, nil
 }
 
@@ -45,25 +165,77 @@ func (d *Airbyte) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 func (d *Airbyte) Validate(ctx context.Context) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
-	_, err := d.client.ListOrganizations(ctx)
-	if err != nil {
-		l.Error("Error listing organizations", zap.Error(err))
-		return nil, err
+	for _, deployment := range d.deployments {
+		orgs, err := deployment.client.ListOrganizations(ctx)
+		if err == nil {
+			// The deployment is probed once, the builders skip what it doesn't support for the rest of the run.
+			err = deployment.capabilities.probe(ctx, deployment.client, orgs)
+		}
+		if err != nil {
+			l.Error("Error validating deployment", zap.String("deployment", deployment.name), zap.Error(err))
+			if deployment.name != "" {
+				return nil, fmt.Errorf("airbyte-connector: deployment %s: %w", deployment.name, err)
+			}
+			return nil, err
+		}
 	}
 
 	return nil, nil
 }
 
-// New returns a new instance of the connector.
-func New(ctx context.Context, hostname string, clientId string, clientSecret string) (*Airbyte, error) {
-	airbyteClient, err := airbyte.NewClient(ctx, hostname, clientId, clientSecret)
+// New returns a new instance of the connector syncing a single Airbyte deployment.
+func New(ctx context.Context, hostname string, clientId string, clientSecret string, opts ...Option) (*Airbyte, error) {
+	return NewWithDeployments(ctx, []Deployment{
+		{
+			Hostname:     hostname,
+			ClientID:     clientId,
+			ClientSecret: clientSecret,
+		},
+	}, opts...)
+}
+
+// NewWithDeployments returns a new instance of the connector syncing several Airbyte deployments.
+// Every deployment needs a unique name, which namespaces the IDs of its resources.
+func NewWithDeployments(ctx context.Context, deployments []Deployment, opts ...Option) (*Airbyte, error) {
+	l := ctxzap.Extract(ctx)
+
+	err := ValidateDeployments(deployments)
 	if err != nil {
-		l := ctxzap.Extract(ctx)
-		l.Error("Error creating Airbyte client", zap.Error(err))
 		return nil, err
 	}
 
-	return &Airbyte{
-		client: airbyteClient,
-	}, nil
+	connector := &Airbyte{}
+	for _, opt := range opts {
+		opt(connector)
+	}
+
+	for _, config := range deployments {
+		auth, err := config.authenticator()
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints, err := config.endpoints()
+		if err != nil {
+			return nil, err
+		}
+
+		clientOpts := append([]airbyte.Option{airbyte.WithEndpoints(endpoints)}, connector.clientOpts...)
+		airbyteClient, err := airbyte.NewClient(ctx, config.Hostname, auth, clientOpts...)
+		if err != nil {
+			l.Error("Error creating Airbyte client", zap.String("deployment", config.Name), zap.Error(err))
+			return nil, err
+		}
+
+		capabilities := newCapabilities()
+		connector.deployments = append(connector.deployments, &deployment{
+			name:           config.Name,
+			client:         airbyteClient,
+			capabilities:   capabilities,
+			workspaceIndex: newWorkspaceIndex(airbyteClient, capabilities),
+			scope:          newSyncScope(airbyteClient, connector.organizationFilter, connector.workspaceFilter),
+		})
+	}
+
+	return connector, nil
 }
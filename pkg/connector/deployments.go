@@ -0,0 +1,747 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Deployment configures an Airbyte deployment synced by the connector.
+type Deployment struct {
+	// Name identifies the deployment and namespaces the IDs of its resources. It may only be empty when the connector
+	// syncs a single deployment, whose resource IDs are then the Airbyte IDs.
+	Name string `json:"name"`
+	// Hostname is the web app of the deployment, it is optional for Airbyte Cloud.
+	Hostname string `json:"hostname"`
+	// Profile locates the APIs of the deployment, one of the airbyte.DeploymentProfile constants. It defaults to a
+	// self-managed deployment, the URLs override the API roots and token endpoint of the profile.
+	Profile      string `json:"profile,omitempty"`
+	PublicAPIURL string `json:"public_api_url,omitempty"`
+	ConfigAPIURL string `json:"config_api_url,omitempty"`
+	TokenURL     string `json:"token_url,omitempty"`
+	// AuthMethod selects how requests are authenticated, one of the airbyte.AuthMethod constants. When it isn't set, it
+	// is inferred from the credentials that are, and defaults to the client credentials of an application.
+	AuthMethod   string `json:"auth_method,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	BearerToken  string `json:"bearer_token,omitempty"`
+	// Username and Password authenticate with the Keycloak password grant, against KeycloakRealm with the
+	// KeycloakClientID client, which default to those of a standard Airbyte installation.
+	Username         string `json:"username,omitempty"`
+	Password         string `json:"password,omitempty"`
+	KeycloakRealm    string `json:"keycloak_realm,omitempty"`
+	KeycloakClientID string `json:"keycloak_client_id,omitempty"`
+}
+
+// endpoints returns where the APIs of the deployment are.
+func (d Deployment) endpoints() (airbyte.Endpoints, error) {
+	endpoints, err := airbyte.ResolveEndpoints(d.Profile, d.Hostname, airbyte.Endpoints{
+		PublicAPIURL: d.PublicAPIURL,
+		ConfigAPIURL: d.ConfigAPIURL,
+		TokenURL:     d.TokenURL,
+	})
+	if err != nil {
+		return airbyte.Endpoints{}, fmt.Errorf("airbyte-connector: deployment %q: %w", d.Name, err)
+	}
+
+	return endpoints, nil
+}
+
+// authMethod returns the auth method of the deployment, inferred from its credentials when none is set.
+func (d Deployment) authMethod() (string, error) {
+	if d.AuthMethod != "" {
+		return d.AuthMethod, nil
+	}
+
+	var methods []string
+	if d.ClientID != "" || d.ClientSecret != "" {
+		methods = append(methods, airbyte.AuthMethodClientCredentials)
+	}
+	if d.BearerToken != "" {
+		methods = append(methods, airbyte.AuthMethodBearerToken)
+	}
+	if d.Username != "" || d.Password != "" {
+		methods = append(methods, airbyte.AuthMethodKeycloakPassword)
+	}
+
+	switch len(methods) {
+	case 0:
+		return airbyte.AuthMethodClientCredentials, nil
+	case 1:
+		return methods[0], nil
+	default:
+		return "", fmt.Errorf(
+			"airbyte-connector: deployment %q has the credentials of several auth methods (%s), select one with auth_method",
+			d.Name,
+			strings.Join(methods, ", "),
+		)
+	}
+}
+
+// authenticator returns the authenticator of the requests to the deployment.
+func (d Deployment) authenticator() (airbyte.Authenticator, error) {
+	method, err := d.authMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	switch method {
+	case airbyte.AuthMethodClientCredentials:
+		if d.ClientID == "" || d.ClientSecret == "" {
+			return nil, fmt.Errorf("airbyte-connector: deployment %q requires a client_id and client_secret, or the credentials of another auth_method", d.Name)
+		}
+		return airbyte.ClientCredentials(d.ClientID, d.ClientSecret), nil
+	case airbyte.AuthMethodBearerToken:
+		if d.BearerToken == "" {
+			return nil, fmt.Errorf("airbyte-connector: deployment %q requires a bearer_token", d.Name)
+		}
+		return airbyte.BearerToken(d.BearerToken), nil
+	case airbyte.AuthMethodKeycloakPassword:
+		if d.Username == "" || d.Password == "" {
+			return nil, fmt.Errorf("airbyte-connector: deployment %q requires a username and password", d.Name)
+		}
+		return airbyte.KeycloakPassword(d.KeycloakRealm, d.KeycloakClientID, d.Username, d.Password), nil
+	case airbyte.AuthMethodNone:
+		return airbyte.NoAuth(), nil
+	default:
+		return nil, fmt.Errorf("airbyte-connector: deployment %q has unsupported auth_method %q", d.Name, d.AuthMethod)
+	}
+}
+
+// deployment is an Airbyte deployment synced by the connector.
+type deployment struct {
+	name   string
+	client *airbyte.Client
+	// capabilities records what the deployment supports, probed when the connector is validated.
+	capabilities *capabilities
+	// workspaceIndex maps workspaces to their organizations for the syncs of the deployment.
+	workspaceIndex *workspaceIndex
+	// scope selects the organizations and workspaces of the deployment that are synced.
+	scope *syncScope
+}
+
+// deploymentSeparator separates the deployment name from the Airbyte ID in the IDs of resources.
+const deploymentSeparator = "/"
+
+var deploymentNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateDeployments checks that deployments are complete, have absolute hostnames and can be told apart by their
+// names.
+func ValidateDeployments(deployments []Deployment) error {
+	if len(deployments) == 0 {
+		return fmt.Errorf("airbyte-connector: at least one deployment is required")
+	}
+
+	for _, d := range deployments {
+		if d.Hostname != "" {
+			u, err := url.Parse(d.Hostname)
+			if err != nil || !u.IsAbs() || u.Host == "" {
+				return fmt.Errorf("airbyte-connector: deployment %q has hostname %q, which must be an absolute URL such as https://airbyte.example.com", d.Name, d.Hostname)
+			}
+		}
+		if _, err := d.endpoints(); err != nil {
+			return err
+		}
+		if _, err := d.authenticator(); err != nil {
+			return err
+		}
+	}
+
+	if len(deployments) == 1 && deployments[0].Name == "" {
+		return nil
+	}
+
+	names := make(map[string]bool, len(deployments))
+	for _, d := range deployments {
+		if !deploymentNamePattern.MatchString(d.Name) {
+			return fmt.Errorf("airbyte-connector: invalid deployment name %q, names must consist of lowercase letters, digits, '-' and '_'", d.Name)
+		}
+		if names[d.Name] {
+			return fmt.Errorf("airbyte-connector: duplicate deployment name %q", d.Name)
+		}
+		names[d.Name] = true
+	}
+
+	return nil
+}
+
+// namespacedID returns the ID of a resource of the deployment.
+func namespacedID(deploymentName, id string) string {
+	return deploymentName + deploymentSeparator + id
+}
+
+// splitNamespacedID returns the deployment name and the Airbyte ID of a namespaced resource ID.
+func splitNamespacedID(id string) (string, string, bool) {
+	return strings.Cut(id, deploymentSeparator)
+}
+
+// -------------------------------------------------------------------------------------------------
+// ID REWRITING
+// -------------------------------------------------------------------------------------------------
+
+// idMapper rewrites the resource part of IDs, e.g. to add or remove the deployment namespace.
+type idMapper func(id string) string
+
+func namespaceMapper(deploymentName string) idMapper {
+	return func(id string) string {
+		return namespacedID(deploymentName, id)
+	}
+}
+
+func unnamespaceMapper(deploymentName string) idMapper {
+	return func(id string) string {
+		return strings.TrimPrefix(id, deploymentName+deploymentSeparator)
+	}
+}
+
+func (m idMapper) resourceID(id *v2.ResourceId) *v2.ResourceId {
+	if id == nil {
+		return nil
+	}
+
+	return &v2.ResourceId{
+		ResourceType:  id.ResourceType,
+		Resource:      m(id.Resource),
+		BatonResource: id.BatonResource,
+	}
+}
+
+func (m idMapper) resource(resource *v2.Resource) *v2.Resource {
+	if resource == nil {
+		return nil
+	}
+
+	rv, _ := proto.Clone(resource).(*v2.Resource)
+	rv.Id = m.resourceID(resource.Id)
+	rv.ParentResourceId = m.resourceID(resource.ParentResourceId)
+
+	// Secret traits link applications to their owner, whose ID is namespaced like any other resource.
+	annos := annotations.Annotations(rv.Annotations)
+	secretTrait := &v2.SecretTrait{}
+	if ok, err := annos.Pick(secretTrait); err == nil && ok {
+		secretTrait.CreatedById = m.resourceID(secretTrait.CreatedById)
+		secretTrait.IdentityId = m.resourceID(secretTrait.IdentityId)
+		annos.Update(secretTrait)
+		rv.Annotations = annos
+	}
+
+	return rv
+}
+
+// entitlementID rewrites an entitlement ID of the form resource_type:resource_id:slug.
+// Resource IDs may contain colons, e.g. an instance host with a port, so the slug is cut at the last colon.
+func (m idMapper) entitlementID(id string) string {
+	resourceType, rest, ok := strings.Cut(id, ":")
+	if !ok {
+		return id
+	}
+
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return id
+	}
+
+	return resourceType + ":" + m(rest[:i]) + rest[i:]
+}
+
+func (m idMapper) entitlement(entitlement *v2.Entitlement) *v2.Entitlement {
+	if entitlement == nil {
+		return nil
+	}
+
+	rv, _ := proto.Clone(entitlement).(*v2.Entitlement)
+	rv.Id = m.entitlementID(entitlement.Id)
+	rv.Resource = m.resource(entitlement.Resource)
+
+	return rv
+}
+
+// grant rewrites the entitlement, principal and sources of a grant, as well as the entitlement IDs referenced by its
+// expandable and immutable annotations.
+func (m idMapper) grant(g *v2.Grant) (*v2.Grant, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	rv, _ := proto.Clone(g).(*v2.Grant)
+	rv.Entitlement = m.entitlement(g.Entitlement)
+	rv.Principal = m.resource(g.Principal)
+
+	// Grant IDs are built from the entitlement and principal IDs, see grant.NewGrant.
+	if g.Entitlement != nil && g.Principal.GetId() != nil &&
+		g.Id == fmt.Sprintf("%s:%s:%s", g.Entitlement.Id, g.Principal.Id.ResourceType, g.Principal.Id.Resource) {
+		rv.Id = fmt.Sprintf("%s:%s:%s", rv.Entitlement.Id, rv.Principal.Id.ResourceType, rv.Principal.Id.Resource)
+	}
+
+	if sources := g.GetSources().GetSources(); sources != nil {
+		rv.Sources = &v2.GrantSources{Sources: make(map[string]*v2.GrantSources_GrantSource, len(sources))}
+		for entitlementID, source := range sources {
+			rv.Sources.Sources[m.entitlementID(entitlementID)] = source
+		}
+	}
+
+	annos := annotations.Annotations(rv.Annotations)
+
+	expandable := &v2.GrantExpandable{}
+	ok, err := annos.Pick(expandable)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for i, entitlementID := range expandable.EntitlementIds {
+			expandable.EntitlementIds[i] = m.entitlementID(entitlementID)
+		}
+		annos.Update(expandable)
+	}
+
+	immutable := &v2.GrantImmutable{}
+	ok, err = annos.Pick(immutable)
+	if err != nil {
+		return nil, err
+	}
+	if ok && immutable.SourceId != "" {
+		immutable.SourceId = m.entitlementID(immutable.SourceId)
+		annos.Update(immutable)
+	}
+
+	rv.Annotations = annos
+
+	return rv, nil
+}
+
+// -------------------------------------------------------------------------------------------------
+// MULTI-DEPLOYMENT SYNCERS
+// -------------------------------------------------------------------------------------------------
+
+// multiDeploymentResourceSyncer combines the syncers of a resource type of several deployments.
+type multiDeploymentResourceSyncer interface {
+	connectorbuilder.ResourceSyncer
+	addDeployment(name string, syncer connectorbuilder.ResourceSyncer)
+}
+
+// multiDeploymentSyncer dispatches calls to the syncer of the deployment a resource belongs to, and namespaces the IDs
+// of the resources, entitlements and grants the syncers return with the deployment name.
+type multiDeploymentSyncer struct {
+	resourceType *v2.ResourceType
+	names        []string
+	syncers      map[string]connectorbuilder.ResourceSyncer
+}
+
+// newMultiDeploymentSyncer returns a syncer combining syncers of the same type as syncer. The SDK discovers
+// provisioning capabilities through type assertions, so the combined syncer implements exactly the optional interfaces
+// syncer implements, each wrapped by its multi-deployment counterpart.
+func newMultiDeploymentSyncer(syncer connectorbuilder.ResourceSyncer) multiDeploymentResourceSyncer {
+	base := &multiDeploymentSyncer{
+		resourceType: syncer.ResourceType(context.Background()),
+		syncers:      make(map[string]connectorbuilder.ResourceSyncer),
+	}
+
+	_, isProvisioner := syncer.(connectorbuilder.ResourceProvisionerV2)
+	_, isResourceManager := syncer.(connectorbuilder.ResourceManager)
+	_, isAccountManager := syncer.(connectorbuilder.AccountManager)
+	_, isCredentialManager := syncer.(connectorbuilder.CredentialManager)
+
+	p := &multiDeploymentProvisioner{base}
+	r := &multiDeploymentResourceManager{base}
+	a := &multiDeploymentAccountManager{base}
+	c := &multiDeploymentCredentialManager{base}
+
+	// Go can't add methods to a type at runtime, so every combination of the optional interfaces has its own type.
+	// The wrappers are embedded next to the base syncer, whose methods are promoted at a shallower depth.
+	switch [4]bool{isProvisioner, isResourceManager, isAccountManager, isCredentialManager} {
+	case [4]bool{true, false, false, false}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+		}{base, p}
+	case [4]bool{false, true, false, false}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentResourceManager
+		}{base, r}
+	case [4]bool{false, false, true, false}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentAccountManager
+		}{base, a}
+	case [4]bool{false, false, false, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentCredentialManager
+		}{base, c}
+	case [4]bool{true, true, false, false}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+			*multiDeploymentResourceManager
+		}{base, p, r}
+	case [4]bool{true, false, true, false}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+			*multiDeploymentAccountManager
+		}{base, p, a}
+	case [4]bool{true, false, false, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+			*multiDeploymentCredentialManager
+		}{base, p, c}
+	case [4]bool{false, true, true, false}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentResourceManager
+			*multiDeploymentAccountManager
+		}{base, r, a}
+	case [4]bool{false, true, false, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentResourceManager
+			*multiDeploymentCredentialManager
+		}{base, r, c}
+	case [4]bool{false, false, true, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentAccountManager
+			*multiDeploymentCredentialManager
+		}{base, a, c}
+	case [4]bool{true, true, true, false}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+			*multiDeploymentResourceManager
+			*multiDeploymentAccountManager
+		}{base, p, r, a}
+	case [4]bool{true, true, false, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+			*multiDeploymentResourceManager
+			*multiDeploymentCredentialManager
+		}{base, p, r, c}
+	case [4]bool{true, false, true, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+			*multiDeploymentAccountManager
+			*multiDeploymentCredentialManager
+		}{base, p, a, c}
+	case [4]bool{false, true, true, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentResourceManager
+			*multiDeploymentAccountManager
+			*multiDeploymentCredentialManager
+		}{base, r, a, c}
+	case [4]bool{true, true, true, true}:
+		return &struct {
+			*multiDeploymentSyncer
+			*multiDeploymentProvisioner
+			*multiDeploymentResourceManager
+			*multiDeploymentAccountManager
+			*multiDeploymentCredentialManager
+		}{base, p, r, a, c}
+	default:
+		return base
+	}
+}
+
+func (m *multiDeploymentSyncer) addDeployment(name string, syncer connectorbuilder.ResourceSyncer) {
+	m.names = append(m.names, name)
+	m.syncers[name] = syncer
+}
+
+func (m *multiDeploymentSyncer) ResourceType(_ context.Context) *v2.ResourceType {
+	return m.resourceType
+}
+
+// deploymentOf returns the deployment name and syncer of a namespaced resource ID.
+func (m *multiDeploymentSyncer) deploymentOf(id string) (string, connectorbuilder.ResourceSyncer, error) {
+	name, _, ok := splitNamespacedID(id)
+	if !ok {
+		return "", nil, fmt.Errorf("airbyte-connector: resource ID %q has no deployment", id)
+	}
+
+	syncer, ok := m.syncers[name]
+	if !ok {
+		return "", nil, fmt.Errorf("airbyte-connector: unknown deployment %q in resource ID %q", name, id)
+	}
+
+	return name, syncer, nil
+}
+
+// List returns the resources of the deployment of the parent resource. Top-level resources are listed from every
+// deployment in turn, with the page token of each deployment kept in the bag.
+func (m *multiDeploymentSyncer) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID != nil {
+		name, syncer, err := m.deploymentOf(parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		resources, next, annos, err := syncer.List(ctx, unnamespaceMapper(name).resourceID(parentResourceID), pToken)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		return namespaceResources(name, resources), next, annos, nil
+	}
+
+	bag := &pagination.Bag{}
+	err := bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if bag.Current() == nil {
+		// The bag is a stack, deployments are pushed in reverse so they are listed in order.
+		for i := len(m.names) - 1; i >= 0; i-- {
+			bag.Push(pagination.PageState{
+				ResourceTypeID: m.resourceType.Id,
+				ResourceID:     m.names[i],
+			})
+		}
+	}
+
+	current := bag.Current()
+	syncer, ok := m.syncers[current.ResourceID]
+	if !ok {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: unknown deployment %q in page token", current.ResourceID)
+	}
+
+	resources, next, annos, err := syncer.List(ctx, nil, &pagination.Token{Size: pToken.Size, Token: current.Token})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if next == "" {
+		bag.Pop()
+	} else {
+		err = bag.Next(next)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	nextToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return namespaceResources(current.ResourceID, resources), nextToken, annos, nil
+}
+
+func (m *multiDeploymentSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	name, syncer, err := m.deploymentOf(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	entitlements, next, annos, err := syncer.Entitlements(ctx, unnamespaceMapper(name).resource(resource), pToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	namespace := namespaceMapper(name)
+	for i, entitlement := range entitlements {
+		entitlements[i] = namespace.entitlement(entitlement)
+	}
+
+	return entitlements, next, annos, nil
+}
+
+func (m *multiDeploymentSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	name, syncer, err := m.deploymentOf(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	grants, next, annos, err := syncer.Grants(ctx, unnamespaceMapper(name).resource(resource), pToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	grants, err = namespaceGrants(name, grants)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return grants, next, annos, nil
+}
+
+func namespaceResources(name string, resources []*v2.Resource) []*v2.Resource {
+	namespace := namespaceMapper(name)
+	for i, resource := range resources {
+		resources[i] = namespace.resource(resource)
+	}
+
+	return resources
+}
+
+func namespaceGrants(name string, grants []*v2.Grant) ([]*v2.Grant, error) {
+	namespace := namespaceMapper(name)
+	for i, g := range grants {
+		namespaced, err := namespace.grant(g)
+		if err != nil {
+			return nil, err
+		}
+		grants[i] = namespaced
+	}
+
+	return grants, nil
+}
+
+// multiDeploymentProvisioner combines resource provisioners of several deployments.
+type multiDeploymentProvisioner struct {
+	*multiDeploymentSyncer
+}
+
+func (m *multiDeploymentProvisioner) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	name, syncer, err := m.deploymentOf(entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if principalName, _, _ := splitNamespacedID(principal.Id.Resource); principalName != name {
+		return nil, nil, fmt.Errorf("airbyte-connector: principal %s doesn't belong to deployment %s", principal.Id.Resource, name)
+	}
+
+	unnamespace := unnamespaceMapper(name)
+	grants, annos, err := syncer.(connectorbuilder.ResourceProvisionerV2).Grant(ctx, unnamespace.resource(principal), unnamespace.entitlement(entitlement))
+	if err != nil {
+		return nil, annos, err
+	}
+
+	grants, err = namespaceGrants(name, grants)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return grants, annos, nil
+}
+
+func (m *multiDeploymentProvisioner) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	name, syncer, err := m.deploymentOf(g.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	unnamespaced, err := unnamespaceMapper(name).grant(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return syncer.(connectorbuilder.ResourceProvisionerV2).Revoke(ctx, unnamespaced)
+}
+
+// multiDeploymentResourceManager combines the resource managers of several deployments.
+// Resources are created in the deployment of their parent and deleted in the deployment of their own ID.
+type multiDeploymentResourceManager struct {
+	*multiDeploymentSyncer
+}
+
+func (m *multiDeploymentResourceManager) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	name, syncer, err := m.deploymentOf(resource.GetParentResourceId().GetResource())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created, annos, err := syncer.(connectorbuilder.ResourceManager).Create(ctx, unnamespaceMapper(name).resource(resource))
+	if err != nil {
+		return nil, annos, err
+	}
+
+	return namespaceMapper(name).resource(created), annos, nil
+}
+
+func (m *multiDeploymentResourceManager) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	name, syncer, err := m.deploymentOf(resourceId.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return syncer.(connectorbuilder.ResourceManager).Delete(ctx, unnamespaceMapper(name).resourceID(resourceId))
+}
+
+// multiDeploymentAccountManager combines the account managers of several deployments.
+// Accounts are created in the deployment of the organization_id from the account profile.
+type multiDeploymentAccountManager struct {
+	*multiDeploymentSyncer
+}
+
+func (m *multiDeploymentAccountManager) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	credentialOptions *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	organizationID, ok := rs.GetProfileStringValue(accountInfo.GetProfile(), "organization_id")
+	if !ok || organizationID == "" {
+		return nil, nil, nil, fmt.Errorf("airbyte-connector: organization_id is required to create an account")
+	}
+
+	name, syncer, err := m.deploymentOf(organizationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// The organization and workspace are referenced by their resource IDs, the deployment syncer expects Airbyte IDs.
+	unnamespace := unnamespaceMapper(name)
+	info, _ := proto.Clone(accountInfo).(*v2.AccountInfo)
+	for _, key := range []string{"organization_id", "workspace_id"} {
+		if value, ok := rs.GetProfileStringValue(info.GetProfile(), key); ok && value != "" {
+			info.Profile.Fields[key] = structpb.NewStringValue(unnamespace(value))
+		}
+	}
+
+	response, plaintexts, annos, err := syncer.(connectorbuilder.AccountManager).CreateAccount(ctx, info, credentialOptions)
+	if err != nil {
+		return nil, nil, annos, err
+	}
+
+	namespace := namespaceMapper(name)
+	switch r := response.(type) {
+	case *v2.CreateAccountResponse_SuccessResult:
+		r.Resource = namespace.resource(r.Resource)
+	case *v2.CreateAccountResponse_ActionRequiredResult:
+		r.Resource = namespace.resource(r.Resource)
+	}
+
+	return response, plaintexts, annos, nil
+}
+
+func (m *multiDeploymentAccountManager) CreateAccountCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return m.syncers[m.names[0]].(connectorbuilder.AccountManager).CreateAccountCapabilityDetails(ctx)
+}
+
+// multiDeploymentCredentialManager combines the credential managers of several deployments.
+type multiDeploymentCredentialManager struct {
+	*multiDeploymentSyncer
+}
+
+func (m *multiDeploymentCredentialManager) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	name, syncer, err := m.deploymentOf(resourceId.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return syncer.(connectorbuilder.CredentialManager).Rotate(ctx, unnamespaceMapper(name).resourceID(resourceId), credentialOptions)
+}
+
+func (m *multiDeploymentCredentialManager) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return m.syncers[m.names[0]].(connectorbuilder.CredentialManager).RotateCapabilityDetails(ctx)
+}
@@ -0,0 +1,173 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	grant "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNamespaceGrant(t *testing.T) {
+	workspace := &v2.Resource{
+		Id:               &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "ws-1"},
+		ParentResourceId: &v2.ResourceId{ResourceType: organizationResourceType.Id, Resource: "org-1"},
+	}
+	principal := &v2.ResourceId{ResourceType: organizationResourceType.Id, Resource: "org-1"}
+	g := grant.NewGrant(workspace, WorkspaceAdmin, principal,
+		grant.WithAnnotation(&v2.GrantExpandable{
+			EntitlementIds: []string{organizationEntitlementID("org-1", OrganizationAdmin)},
+		}),
+	)
+
+	namespaced, err := namespaceMapper("eu").grant(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "workspace:eu/ws-1:" + WorkspaceAdmin; namespaced.Entitlement.Id != expected {
+		t.Fatalf("expected entitlement ID %q, got %q", expected, namespaced.Entitlement.Id)
+	}
+	if expected := "workspace:eu/ws-1:" + WorkspaceAdmin + ":organization:eu/org-1"; namespaced.Id != expected {
+		t.Fatalf("expected grant ID %q, got %q", expected, namespaced.Id)
+	}
+	if namespaced.Entitlement.Resource.ParentResourceId.Resource != "eu/org-1" {
+		t.Fatalf("expected namespaced parent, got %q", namespaced.Entitlement.Resource.ParentResourceId.Resource)
+	}
+
+	expandable := &v2.GrantExpandable{}
+	annos := annotations.Annotations(namespaced.Annotations)
+	if _, err := annos.Pick(expandable); err != nil {
+		t.Fatal(err)
+	}
+	if expected := organizationEntitlementID("eu/org-1", OrganizationAdmin); expandable.EntitlementIds[0] != expected {
+		t.Fatalf("expected expandable entitlement %q, got %q", expected, expandable.EntitlementIds[0])
+	}
+
+	restored, err := unnamespaceMapper("eu").grant(namespaced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(restored, g) {
+		t.Fatalf("expected %v, got %v", g, restored)
+	}
+}
+
+func TestNamespaceApplication(t *testing.T) {
+	application, err := applicationResource(&airbyte.Application{ID: "app-1", Name: "ci"}, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespaced := namespaceMapper("eu").resource(application)
+
+	secretTrait := &v2.SecretTrait{}
+	annos := annotations.Annotations(namespaced.Annotations)
+	if _, err := annos.Pick(secretTrait); err != nil {
+		t.Fatal(err)
+	}
+	if secretTrait.CreatedById.GetResource() != "eu/user-1" {
+		t.Fatalf("expected namespaced creator, got %q", secretTrait.CreatedById.GetResource())
+	}
+	if secretTrait.IdentityId.GetResource() != "eu/user-1" {
+		t.Fatalf("expected namespaced identity, got %q", secretTrait.IdentityId.GetResource())
+	}
+
+	restored := unnamespaceMapper("eu").resource(namespaced)
+	if !proto.Equal(restored, application) {
+		t.Fatalf("expected the application to be restored, got %v", restored)
+	}
+}
+
+func TestValidateDeployments(t *testing.T) {
+	deployment := func(name string) Deployment {
+		return Deployment{Name: name, Hostname: "https://airbyte.example.com", ClientID: "id", ClientSecret: "secret"}
+	}
+
+	testCases := []struct {
+		message     string
+		deployments []Deployment
+		isValid     bool
+	}{
+		{"single unnamed deployment", []Deployment{deployment("")}, true},
+		{"named deployments", []Deployment{deployment("eu"), deployment("us")}, true},
+		{"no deployment", nil, false},
+		{"unnamed deployment among several", []Deployment{deployment("eu"), deployment("")}, false},
+		{"duplicate names", []Deployment{deployment("eu"), deployment("eu")}, false},
+		{"name with separator", []Deployment{deployment("eu/1")}, false},
+		{"missing credentials", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com"}}, false},
+		{"bearer token", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com", AuthMethod: airbyte.AuthMethodBearerToken, BearerToken: "token"}}, true},
+		{"keycloak password", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com", AuthMethod: airbyte.AuthMethodKeycloakPassword, Username: "jane", Password: "secret"}}, true},
+		{"keycloak without password", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com", AuthMethod: airbyte.AuthMethodKeycloakPassword, Username: "jane"}}, false},
+		{"inferred bearer token", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com", BearerToken: "token"}}, true},
+		{"inferred keycloak password", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com", Username: "jane", Password: "secret"}}, true},
+		{"credentials of several auth methods", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com", ClientID: "client", ClientSecret: "secret", BearerToken: "token"}}, false},
+		{"no authentication", []Deployment{{Name: "eu", Hostname: "http://localhost:8000", AuthMethod: airbyte.AuthMethodNone}}, true},
+		{"schemeless hostname", []Deployment{{Name: "eu", Hostname: "airbyte.example.com", ClientID: "id", ClientSecret: "secret"}}, false},
+		{"hostname with port but no scheme", []Deployment{{Name: "eu", Hostname: "localhost:8000", AuthMethod: airbyte.AuthMethodNone}}, false},
+		{"unsupported auth method", []Deployment{{Name: "eu", Hostname: "https://airbyte.example.com", AuthMethod: "basic"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			err := ValidateDeployments(tc.deployments)
+			if (err == nil) != tc.isValid {
+				t.Fatalf("expected valid %t, got error %v", tc.isValid, err)
+			}
+		})
+	}
+}
+
+// rotatingOrgBuilder is an organization builder that also rotates credentials, a combination no builder has yet.
+type rotatingOrgBuilder struct {
+	*orgBuilder
+}
+
+func (b *rotatingOrgBuilder) Rotate(_ context.Context, _ *v2.ResourceId, _ *v2.CredentialOptions) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func (b *rotatingOrgBuilder) RotateCapabilityDetails(_ context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func TestNewMultiDeploymentSyncer(t *testing.T) {
+	testCases := []struct {
+		message           string
+		syncer            connectorbuilder.ResourceSyncer
+		provisioner       bool
+		resourceManager   bool
+		accountManager    bool
+		credentialManager bool
+	}{
+		{"syncer only", &connectionBuilder{}, false, false, false, false},
+		{"provisioner", &orgBuilder{}, true, false, false, false},
+		{"resource manager", &invitationBuilder{}, false, true, false, false},
+		{"resource and account manager", &userBuilder{}, false, true, true, false},
+		{"credential manager", &applicationBuilder{}, false, false, false, true},
+		{"provisioner and credential manager", &rotatingOrgBuilder{&orgBuilder{}}, true, false, false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			syncer := newMultiDeploymentSyncer(tc.syncer)
+
+			if _, ok := syncer.(connectorbuilder.ResourceProvisionerV2); ok != tc.provisioner {
+				t.Errorf("expected provisioner %t, got %t", tc.provisioner, ok)
+			}
+			if _, ok := syncer.(connectorbuilder.ResourceManager); ok != tc.resourceManager {
+				t.Errorf("expected resource manager %t, got %t", tc.resourceManager, ok)
+			}
+			if _, ok := syncer.(connectorbuilder.AccountManager); ok != tc.accountManager {
+				t.Errorf("expected account manager %t, got %t", tc.accountManager, ok)
+			}
+			if _, ok := syncer.(connectorbuilder.CredentialManager); ok != tc.credentialManager {
+				t.Errorf("expected credential manager %t, got %t", tc.credentialManager, ok)
+			}
+		})
+	}
+}
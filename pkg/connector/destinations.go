@@ -0,0 +1,102 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+type destinationBuilder struct {
+	resourceType *v2.ResourceType
+	client       *airbyte.Client
+}
+
+func (o *destinationBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return destinationResourceType
+}
+
+// Create a new connector resource for an Airbyte destination.
+func destinationResource(destination *airbyte.DestinationResponse, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"destination_id":   destination.ID,
+		"name":             destination.Name,
+		"destination_type": destination.DestinationType,
+		"definition_id":    destination.DefinitionID,
+		"workspace_id":     destination.WorkspaceID,
+	}
+
+	if destination.CreatedAt > 0 {
+		profile["created_at"] = destination.CreatedAt
+	}
+
+	resource, err := rs.NewAppResource(
+		destination.Name,
+		destinationResourceType,
+		destination.ID,
+		[]rs.AppTraitOption{rs.WithAppProfile(profile)},
+		rs.WithParentResourceID(parentResourceID),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns the destinations of the parent workspace.
+func (o *destinationBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	// pToken.Token is the offset for the current page
+	bag, offsetForCurrentPage, err := parsePageToken(pToken, &v2.ResourceId{ResourceType: destinationResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	destinations, offsetForNextPage, err := o.client.ListDestinationsByWorkspace(ctx, parentResourceID.Resource, ResourcesPageSize, offsetForCurrentPage)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list destinations under workspace %s: %w", parentResourceID.Resource, err)
+	}
+
+	next, err := bag.NextToken(offsetForNextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resources := make([]*v2.Resource, 0, len(destinations))
+	for _, destination := range destinations {
+		resource, err := destinationResource(destination, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource for destination %s: %w", destination.Name, err)
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, next, rateLimitAnnotations(o.client), nil
+}
+
+// Entitlements always returns an empty slice for destinations.
+func (o *destinationBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for destinations since they don't have any entitlements.
+func (o *destinationBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newDestinationBuilder(client *airbyte.Client) *destinationBuilder {
+	return &destinationBuilder{
+		resourceType: destinationResourceType,
+		client:       client,
+	}
+}
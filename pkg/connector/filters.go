@@ -0,0 +1,218 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+)
+
+// ResourceFilter selects organizations or workspaces by ID, name glob or regular expression.
+//
+// Patterns wrapped in slashes, e.g. /^prod-/, are regular expressions. Patterns containing *, ? or [ are globs.
+// Any other pattern is an exact ID or name. Patterns are matched against both the ID and the name.
+type ResourceFilter struct {
+	include []resourceMatcher
+	exclude []resourceMatcher
+}
+
+type resourceMatcher func(value string) bool
+
+// NewResourceFilter returns a filter selecting the resources that match any include pattern, or every resource when
+// there is none, and no exclude pattern.
+func NewResourceFilter(include, exclude []string) (*ResourceFilter, error) {
+	f := &ResourceFilter{}
+
+	for _, pattern := range include {
+		m, err := newResourceMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.include = append(f.include, m)
+	}
+
+	for _, pattern := range exclude {
+		m, err := newResourceMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = append(f.exclude, m)
+	}
+
+	return f, nil
+}
+
+func newResourceMatcher(pattern string) (resourceMatcher, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("airbyte-connector: empty filter pattern")
+	}
+
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("airbyte-connector: invalid filter regular expression %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return nil, fmt.Errorf("airbyte-connector: invalid filter glob %q: %w", pattern, err)
+		}
+		return func(value string) bool {
+			matched, _ := path.Match(pattern, value)
+			return matched
+		}, nil
+	}
+
+	return func(value string) bool {
+		return value == pattern
+	}, nil
+}
+
+// Includes returns whether the resource with the given ID and name is selected. A nil filter selects every resource.
+func (f *ResourceFilter) Includes(id, name string) bool {
+	if f == nil {
+		return true
+	}
+
+	matches := func(matchers []resourceMatcher) bool {
+		for _, m := range matchers {
+			if m(id) || m(name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(f.include) > 0 && !matches(f.include) {
+		return false
+	}
+
+	return !matches(f.exclude)
+}
+
+// empty returns whether the filter selects every resource.
+func (f *ResourceFilter) empty() bool {
+	return f == nil || (len(f.include) == 0 && len(f.exclude) == 0)
+}
+
+// syncScope decides which organizations and workspaces of a deployment are synced.
+//
+// A workspace is synced when it matches the workspace filter and its organization is synced, so filtered resources
+// never appear as parents or grant targets. Workspaces whose organization can't be resolved belong to the synthetic
+// unattributed organization, which the organization filter applies to as well.
+//
+// Organization filters may match names, which the workspace listing doesn't know, so the scope keeps the names of
+// the organizations. Like the workspace index, they are loaded lazily and refreshed at the start of every sync.
+type syncScope struct {
+	client        *airbyte.Client
+	organizations *ResourceFilter
+	workspaces    *ResourceFilter
+
+	mu sync.Mutex
+	// organizationNames is nil until the organizations are listed.
+	organizationNames map[string]string
+}
+
+func newSyncScope(client *airbyte.Client, organizations, workspaces *ResourceFilter) *syncScope {
+	return &syncScope{
+		client:        client,
+		organizations: organizations,
+		workspaces:    workspaces,
+	}
+}
+
+// setOrganizations records the names of the organizations, e.g. when the organization builder lists them.
+func (s *syncScope) setOrganizations(orgs []*airbyte.Organization) {
+	names := organizationNames(orgs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.organizationNames = names
+}
+
+func organizationNames(orgs []*airbyte.Organization) map[string]string {
+	names := make(map[string]string, len(orgs))
+	for _, org := range orgs {
+		names[org.ID] = org.Name
+	}
+
+	return names
+}
+
+// includesOrganization returns whether the organization is synced.
+func (s *syncScope) includesOrganization(ctx context.Context, organizationID string) (bool, error) {
+	if s.organizations.empty() {
+		return true, nil
+	}
+
+	if organizationID == unattributedOrganizationID {
+		return s.organizations.Includes(unattributedOrganizationID, unattributedOrganizationName), nil
+	}
+
+	names, err := s.names(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return s.organizations.Includes(organizationID, names[organizationID]), nil
+}
+
+// emitsOrganization returns whether the organization is synced as a resource, i.e. the application can see it and
+// it is included. The synthetic unattributed organization has no roles, so grants never reference it.
+func (s *syncScope) emitsOrganization(ctx context.Context, organizationID string) (bool, error) {
+	if organizationID == "" || organizationID == unattributedOrganizationID {
+		return false, nil
+	}
+
+	names, err := s.names(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	name, ok := names[organizationID]
+	if !ok {
+		return false, nil
+	}
+
+	return s.organizations.Includes(organizationID, name), nil
+}
+
+// names returns the names of the organizations by ID, listing the organizations if they aren't known yet.
+// The returned map must not be modified.
+func (s *syncScope) names(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.organizationNames == nil {
+		orgs, err := s.client.ListOrganizations(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("airbyte-connector: failed to list organizations: %w", err)
+		}
+		s.organizationNames = organizationNames(orgs)
+	}
+
+	return s.organizationNames, nil
+}
+
+// includesWorkspace returns whether the workspace of the organization is synced. Workspaces without an organization
+// belong to the unattributed organization.
+func (s *syncScope) includesWorkspace(ctx context.Context, workspaceID, workspaceName, organizationID string) (bool, error) {
+	if !s.workspaces.Includes(workspaceID, workspaceName) {
+		return false, nil
+	}
+
+	if organizationID == "" {
+		organizationID = unattributedOrganizationID
+	}
+
+	return s.includesOrganization(ctx, organizationID)
+}
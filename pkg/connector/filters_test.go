@@ -0,0 +1,45 @@
+package connector
+
+import (
+	"testing"
+)
+
+func TestResourceFilter(t *testing.T) {
+	testCases := []struct {
+		message  string
+		include  []string
+		exclude  []string
+		id       string
+		name     string
+		included bool
+	}{
+		{"no patterns", nil, nil, "ws-1", "Sandbox", true},
+		{"included by ID", []string{"ws-1"}, nil, "ws-1", "Production", true},
+		{"not included", []string{"ws-2"}, nil, "ws-1", "Production", false},
+		{"included by glob", []string{"Prod*"}, nil, "ws-1", "Production", true},
+		{"included by regular expression", []string{"/^prod/"}, nil, "ws-1", "production-eu", true},
+		{"excluded by name", nil, []string{"Sandbox"}, "ws-1", "Sandbox", false},
+		{"exclude wins over include", []string{"/.*/"}, []string{"ws-1"}, "ws-1", "Production", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			f, err := NewResourceFilter(tc.include, tc.exclude)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if included := f.Includes(tc.id, tc.name); included != tc.included {
+				t.Fatalf("expected included %t, got %t", tc.included, included)
+			}
+		})
+	}
+}
+
+func TestResourceFilterInvalidPatterns(t *testing.T) {
+	for _, pattern := range []string{"", "/[/", "prod-["} {
+		_, err := NewResourceFilter([]string{pattern}, nil)
+		if err == nil {
+			t.Fatalf("expected an error for pattern %q", pattern)
+		}
+	}
+}
@@ -1,8 +1,16 @@
 package connector
 
 import (
+	"context"
+	"sync"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
 const ResourcesPageSize uint64 = 50
@@ -26,3 +34,61 @@ func parsePageToken(pagToken *pagination.Token, resourceID *v2.ResourceId) (*pag
 
 	return bag, bag.PageToken(), nil
 }
+
+// rateLimitAnnotations returns the latest rate limit state reported by Airbyte as annotations, so the SDK can slow
+// down the whole sync before Airbyte starts rejecting requests.
+func rateLimitAnnotations(client *airbyte.Client) annotations.Annotations {
+	var annos annotations.Annotations
+
+	if rateLimit := client.RateLimitDescription(); rateLimit != nil {
+		annos.Update(rateLimit)
+	}
+
+	return annos
+}
+
+// clearHTTPCaches clears the GET responses cached by the HTTP client, so provisioning reads the current state instead of
+// a response cached before an earlier grant or revoke.
+func clearHTTPCaches(ctx context.Context) {
+	if err := uhttp.ClearCaches(ctx); err != nil {
+		ctxzap.Extract(ctx).Warn("airbyte-connector: failed to clear http caches", zap.Error(err))
+	}
+}
+
+// lookupConcurrency bounds the requests in flight when a value has to be looked up per item, e.g. per user.
+const lookupConcurrency = 8
+
+// forEachConcurrently calls fn for every index in [0, n) with at most lookupConcurrency calls in flight.
+// The first error cancels the context passed to the remaining calls and is returned.
+func forEachConcurrently(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	workers := make(chan struct{}, lookupConcurrency)
+	for i := 0; i < n; i++ {
+		workers <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			if err := fn(ctx, i); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
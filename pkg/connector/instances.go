@@ -0,0 +1,158 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grant "github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type instanceBuilder struct {
+	resourceType *v2.ResourceType
+	client       *airbyte.Client
+}
+
+func (o *instanceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return instanceResourceType
+}
+
+// Create a new connector resource for an Airbyte instance.
+// The instance is named after the URL from its configuration, or the hostname the connector connects to when the
+// deployment doesn't expose its configuration, e.g. on Airbyte Cloud.
+func instanceResource(client *airbyte.Client, config *airbyte.InstanceConfigurationResponse) (*v2.Resource, error) {
+	name := client.Host()
+	profile := map[string]interface{}{
+		"host": client.Host(),
+	}
+
+	if config != nil {
+		if u, err := url.Parse(config.AirbyteURL); err == nil && u.Host != "" {
+			name = u.Host
+		}
+
+		profile["edition"] = config.Edition
+		profile["version"] = config.Version
+		profile["license_type"] = config.LicenseType
+		profile["airbyte_url"] = config.AirbyteURL
+		profile["default_organization_id"] = config.DefaultOrganizationID
+		if config.Auth != nil {
+			profile["auth_mode"] = config.Auth.Mode
+		}
+	}
+
+	return rs.NewAppResource(
+		fmt.Sprintf("Airbyte (%s)", name),
+		instanceResourceType,
+		client.Host(),
+		[]rs.AppTraitOption{rs.WithAppProfile(profile)},
+		rs.WithAnnotation(&v2.ChildResourceType{
+			ResourceTypeId: organizationResourceType.Id,
+		}),
+	)
+}
+
+// List returns the Airbyte instance the connector connects to.
+func (o *instanceBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID != nil {
+		return nil, "", nil, nil
+	}
+
+	config, err := o.getInstanceConfiguration(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resource, err := instanceResource(o.client, config)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create resource for instance %s: %w", o.client.Host(), err)
+	}
+
+	return []*v2.Resource{resource}, "", rateLimitAnnotations(o.client), nil
+}
+
+// Entitlements returns the instance_admin entitlement of the instance.
+func (o *instanceBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	entitlement := ent.NewPermissionEntitlement(
+		resource,
+		airbyte.InstanceAdmin,
+		ent.WithGrantableTo(userResourceType),
+		ent.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, airbyte.InstanceAdmin)),
+		ent.WithDescription(fmt.Sprintf("Administrator of every organization and workspace of %s", resource.DisplayName)),
+	)
+
+	return []*v2.Entitlement{entitlement}, "", nil, nil
+}
+
+// Grants returns a grant of instance_admin for every instance administrator.
+//
+// The administrators are listed at once, including those that don't belong to any organization. Deployments that
+// don't expose their instance configuration, e.g. Airbyte Cloud, have no instance administrators and are skipped.
+func (o *instanceBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	config, err := o.getInstanceConfiguration(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if config == nil {
+		return nil, "", nil, nil
+	}
+
+	admins, err := o.client.ListInstanceAdmins(ctx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list instance administrators: %w", err)
+	}
+
+	rv := make([]*v2.Grant, 0, len(admins))
+	seen := make(map[string]bool, len(admins))
+	for _, admin := range admins {
+		if seen[admin.UserID] {
+			continue
+		}
+		seen[admin.UserID] = true
+
+		rv = append(rv, grant.NewGrant(resource, airbyte.InstanceAdmin, &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     admin.UserID,
+		}))
+	}
+
+	return rv, "", rateLimitAnnotations(o.client), nil
+}
+
+func newInstanceBuilder(client *airbyte.Client) *instanceBuilder {
+	return &instanceBuilder{
+		resourceType: instanceResourceType,
+		client:       client,
+	}
+}
+
+// -------------------------------------------------------------------------------------------------
+// PRIVATE HELPER FUNCTIONS
+// -------------------------------------------------------------------------------------------------
+
+// getInstanceConfiguration returns the configuration of the instance, or nil if the deployment doesn't expose it.
+func (o *instanceBuilder) getInstanceConfiguration(ctx context.Context) (*airbyte.InstanceConfigurationResponse, error) {
+	config, err := o.client.GetInstanceConfiguration(ctx)
+	if err != nil {
+		// Airbyte Cloud doesn't expose the instance configuration to applications.
+		switch status.Code(err) {
+		case codes.NotFound, codes.PermissionDenied, codes.Unimplemented:
+			ctxzap.Extract(ctx).Debug("airbyte-connector: instance configuration is not available", zap.Error(err))
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("airbyte-connector: failed to get the instance configuration: %w", err)
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,230 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type invitationBuilder struct {
+	resourceType *v2.ResourceType
+	client       *airbyte.Client
+}
+
+func (o *invitationBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return invitationResourceType
+}
+
+// Create a new connector resource for a pending Airbyte invitation.
+// Invitations are synced as secrets rather than users, so an invited person isn't represented both by the invitation and
+// by their Airbyte user. The invitation is linked to the user who sent it. The invite code lets anyone holding it accept
+// the invitation, so it is never synced.
+func invitationResource(invitation *airbyte.UserInvitationReadResponse, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	var secretTraitOptions []rs.SecretTraitOption
+
+	if invitation.CreatedAt > 0 {
+		secretTraitOptions = append(secretTraitOptions, rs.WithSecretCreatedAt(time.Unix(invitation.CreatedAt, 0)))
+	}
+
+	if invitation.InviterUserID != "" {
+		secretTraitOptions = append(secretTraitOptions, rs.WithSecretCreatedByID(&v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     invitation.InviterUserID,
+		}))
+	}
+
+	description := fmt.Sprintf("Invitation of %s as %s of %s %s", invitation.InvitedEmail, strings.ToLower(invitation.PermissionType), invitation.ScopeType, invitation.ScopeName)
+	if invitation.ExpiresAt > 0 {
+		expiresAt := time.Unix(invitation.ExpiresAt, 0).UTC()
+		secretTraitOptions = append(secretTraitOptions, rs.WithSecretExpiresAt(expiresAt))
+		description += fmt.Sprintf(", expiring %s", expiresAt.Format(time.RFC3339))
+	}
+
+	resource, err := rs.NewSecretResource(
+		invitation.InvitedEmail,
+		invitationResourceType,
+		invitation.ID,
+		secretTraitOptions,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithDescription(description),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns the pending invitations of the parent organization or workspace.
+func (o *invitationBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil || parentResourceID.Resource == unattributedOrganizationID {
+		return nil, "", nil, nil
+	}
+
+	var scopeType string
+	switch parentResourceID.ResourceType {
+	case organizationResourceType.Id:
+		scopeType = airbyte.PermissionScopeOrganization
+	case workspaceResourceType.Id:
+		scopeType = airbyte.PermissionScopeWorkspace
+	default:
+		return nil, "", nil, nil
+	}
+
+	invitations, err := o.listPendingInvitations(ctx, scopeType, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resources := make([]*v2.Resource, 0, len(invitations))
+	for _, invitation := range invitations {
+		resource, err := invitationResource(invitation, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource for invitation %s: %w", invitation.ID, err)
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, "", rateLimitAnnotations(o.client), nil
+}
+
+// Entitlements always returns an empty slice for invitations.
+func (o *invitationBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for invitations since they don't have any entitlements.
+func (o *invitationBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Create is not supported for invitations, users are invited through account provisioning instead.
+func (o *invitationBuilder) Create(_ context.Context, _ *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	return nil, nil, status.Error(codes.Unimplemented, "airbyte-connector: invitations are created through account provisioning")
+}
+
+// Delete cancels a pending invitation.
+// The invite code needed to cancel it isn't synced, so the invitation is looked up again in every organization and
+// workspace. An invitation that is no longer pending, e.g. because it was accepted or already cancelled, is treated
+// as deleted.
+func (o *invitationBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != invitationResourceType.Id {
+		return nil, fmt.Errorf("airbyte-connector: unsupported resource type %s", resourceId.ResourceType)
+	}
+
+	invitationID := resourceId.Resource
+
+	// Invitations are read with the HTTP client cache, which could still hold an invitation cancelled earlier.
+	clearHTTPCaches(ctx)
+
+	invitation, err := o.findPendingInvitation(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil {
+		ctxzap.Extract(ctx).Debug("airbyte-connector: invitation is no longer pending", zap.String("invitation_id", invitationID))
+		return nil, nil
+	}
+
+	err = o.client.CancelUserInvitation(ctx, invitation.InviteCode)
+	if err != nil {
+		return nil, fmt.Errorf("airbyte-connector: failed to cancel invitation %s: %w", invitationID, err)
+	}
+
+	return nil, nil
+}
+
+func newInvitationBuilder(client *airbyte.Client) *invitationBuilder {
+	return &invitationBuilder{
+		resourceType: invitationResourceType,
+		client:       client,
+	}
+}
+
+// -------------------------------------------------------------------------------------------------
+// PRIVATE HELPER FUNCTIONS
+// -------------------------------------------------------------------------------------------------
+
+// listPendingInvitations returns the pending invitations of the scope, or none if the deployment doesn't serve them to
+// the application, e.g. because the endpoint is private on Airbyte Cloud or the application isn't allowed to read them.
+func (o *invitationBuilder) listPendingInvitations(ctx context.Context, scopeType, scopeID string) ([]*airbyte.UserInvitationReadResponse, error) {
+	invitations, err := o.client.ListPendingInvitations(ctx, scopeType, scopeID)
+	if err != nil {
+		if !isUnsupported(err) {
+			return nil, fmt.Errorf("airbyte-connector: failed to list invitations under %s %s: %w", scopeType, scopeID, err)
+		}
+		ctxzap.Extract(ctx).Warn(
+			"airbyte-connector: the invitations of scope can't be read",
+			zap.String("scope_type", scopeType),
+			zap.String("scope_id", scopeID),
+			zap.Error(err),
+		)
+		return nil, nil
+	}
+
+	return invitations, nil
+}
+
+// findPendingInvitation returns the pending invitation with the ID, or nil if there is none.
+func (o *invitationBuilder) findPendingInvitation(ctx context.Context, invitationID string) (*airbyte.UserInvitationReadResponse, error) {
+	find := func(invitations []*airbyte.UserInvitationReadResponse) *airbyte.UserInvitationReadResponse {
+		for _, invitation := range invitations {
+			if invitation.ID == invitationID {
+				return invitation
+			}
+		}
+		return nil
+	}
+
+	orgs, err := o.client.ListOrganizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("airbyte-connector: failed to list organizations: %w", err)
+	}
+
+	for _, org := range orgs {
+		invitations, err := o.listPendingInvitations(ctx, airbyte.PermissionScopeOrganization, org.ID)
+		if err != nil {
+			return nil, err
+		}
+		if invitation := find(invitations); invitation != nil {
+			return invitation, nil
+		}
+	}
+
+	offset := ""
+	for {
+		workspaces, nextOffset, err := o.client.ListAllWorkspaces(ctx, ResourcesPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("airbyte-connector: failed to list workspaces: %w", err)
+		}
+
+		for _, workspace := range workspaces {
+			invitations, err := o.listPendingInvitations(ctx, airbyte.PermissionScopeWorkspace, workspace.ID)
+			if err != nil {
+				return nil, err
+			}
+			if invitation := find(invitations); invitation != nil {
+				return invitation, nil
+			}
+		}
+
+		if nextOffset == "" || nextOffset == offset {
+			return nil, nil
+		}
+		offset = nextOffset
+	}
+}
@@ -0,0 +1,98 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestInvitationResource(t *testing.T) {
+	invitation := &airbyte.UserInvitationReadResponse{
+		ID:             "invitation-1",
+		InviteCode:     "secret-code",
+		InvitedEmail:   "jane@example.com",
+		InviterUserID:  "user-1",
+		ScopeType:      airbyte.PermissionScopeWorkspace,
+		ScopeID:        "workspace-1",
+		ScopeName:      "Analytics",
+		PermissionType: "WORKSPACE_ADMIN",
+		ExpiresAt:      1735689600,
+	}
+	parent := &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "workspace-1"}
+
+	resource, err := invitationResource(invitation, parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resource.Id.Resource != "invitation-1" || resource.ParentResourceId.Resource != "workspace-1" {
+		t.Fatalf("unexpected resource IDs %v under %v", resource.Id, resource.ParentResourceId)
+	}
+
+	if _, err := rs.GetUserTrait(resource); err == nil {
+		t.Fatal("expected the invitation not to be synced as a user")
+	}
+
+	secretTrait := &v2.SecretTrait{}
+	annos := annotations.Annotations(resource.Annotations)
+	if ok, err := annos.Pick(secretTrait); err != nil || !ok {
+		t.Fatalf("expected a secret trait: %v", err)
+	}
+	if secretTrait.ExpiresAt.AsTime().Format(time.RFC3339) != "2025-01-01T00:00:00Z" {
+		t.Fatalf("unexpected expiry %s", secretTrait.ExpiresAt.AsTime())
+	}
+	if secretTrait.CreatedById.GetResource() != "user-1" || secretTrait.CreatedById.GetResourceType() != userResourceType.Id {
+		t.Fatalf("unexpected creator %v", secretTrait.CreatedById)
+	}
+	if strings.Contains(resource.Description, invitation.InviteCode) {
+		t.Fatal("invite code synced in the description")
+	}
+}
+
+func TestInvitationListSkipsUnservedScopes(t *testing.T) {
+	testCases := []struct {
+		message    string
+		statusCode int
+	}{
+		{"endpoint not found", http.StatusNotFound},
+		{"application not authenticated", http.StatusUnauthorized},
+		{"application not allowed", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			ctx := context.Background()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/user_invitations/list_pending" {
+					t.Errorf("unexpected request %s", r.URL.Path)
+				}
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			client, err := airbyte.NewClient(ctx, server.URL, airbyte.NoAuth(), airbyte.WithRetryPolicy(airbyte.RetryPolicy{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			builder := newInvitationBuilder(client)
+			resources, _, _, err := builder.List(ctx, &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "workspace-1"}, &pagination.Token{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resources) != 0 {
+				t.Fatalf("expected no invitations, got %v", resources)
+			}
+		})
+	}
+}
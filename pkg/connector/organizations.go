@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/conductorone/baton-airbyte/pkg/airbyte"
@@ -13,6 +14,10 @@ import (
 	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	grant "github.com/conductorone/baton-sdk/pkg/types/grant"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Define organization permission type constants.
@@ -34,9 +39,19 @@ var PublicOrganizationPermissionsTypes = []string{
 	OrganizationMember,
 }
 
+// unattributedOrganizationID is the ID of the synthetic organization that parents workspaces whose organization
+// can't be resolved, e.g. because the application has no access to it.
+const unattributedOrganizationID = "unattributed"
+
+const unattributedOrganizationName = "Unassigned / inaccessible organization"
+
 type orgBuilder struct {
-	resourceType *v2.ResourceType
-	client       *airbyte.Client
+	resourceType                   *v2.ResourceType
+	client                         *airbyte.Client
+	index                          *workspaceIndex
+	scope                          *syncScope
+	capabilities                   *capabilities
+	topLevelUnattributedWorkspaces bool
 }
 
 func (o *orgBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -44,11 +59,15 @@ func (o *orgBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 }
 
 // Create a new connector resource for an airbyte organization.
-func orgResource(org airbyte.Organization) (*v2.Resource, error) {
+func orgResource(org airbyte.Organization, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
 	resource, err := rs.NewResource(
 		org.Name,
 		organizationResourceType,
 		org.ID,
+		rs.WithAnnotation(&v2.ChildResourceType{
+			ResourceTypeId: invitationResourceType.Id,
+		}),
+		rs.WithParentResourceID(parentResourceID),
 	)
 
 	if err != nil {
@@ -58,22 +77,47 @@ func orgResource(org airbyte.Organization) (*v2.Resource, error) {
 	return resource, nil
 }
 
-// List returns all the organizations.
-func (o *orgBuilder) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+// unattributedOrgResource creates the synthetic organization that parents workspaces whose organization can't be
+// resolved. Airbyte has no such organization, so it has neither roles nor members.
+func unattributedOrgResource(workspaceCount int, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	return rs.NewResource(
+		unattributedOrganizationName,
+		organizationResourceType,
+		unattributedOrganizationID,
+		rs.WithDescription(fmt.Sprintf(
+			"Synthetic organization holding %d workspace(s) whose Airbyte organization the connector can't access or resolve.",
+			workspaceCount,
+		)),
+		rs.WithParentResourceID(parentResourceID),
+	)
+}
+
+// List returns all the organizations of the parent instance.
+func (o *orgBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
 	orgs, err := o.client.ListOrganizations(ctx)
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list organizations: %w", err)
 	}
 
+	o.scope.setOrganizations(orgs)
+
 	// Iterate over organizations and filter valid ones
 	resources := make([]*v2.Resource, 0, len(orgs))
 	for _, org := range orgs {
+		if !o.scope.organizations.Includes(org.ID, org.Name) {
+			continue
+		}
+
 		org := airbyte.Organization{
 			ID:   org.ID,
 			Name: org.Name,
 		}
 		// Convert organization to a v2.Resource
-		resource, err := orgResource(org)
+		resource, err := orgResource(org, parentResourceID)
 		if err != nil {
 			return nil, "", nil, fmt.Errorf("failed to create resource for organization %s: %w", org.Name, err)
 		}
@@ -81,11 +125,71 @@ func (o *orgBuilder) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.T
 		resources = append(resources, resource)
 	}
 
-	return resources, "", nil, nil
+	// Organizations are the root of the resource graph and listed first, so the workspace index is rebuilt here for
+	// the rest of the sync.
+	err = o.index.refresh(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	annos := rateLimitAnnotations(o.client)
+
+	// Without the private workspace listing every workspace would be unattributed, they are listed at the top level
+	// instead of under the synthetic organization.
+	if !o.capabilities.has(capabilityWorkspacesByOrganization) {
+		annos.Append(skippedAnnotation(capabilityWorkspacesByOrganization, "parenting workspaces to their organization"))
+		return resources, "", annos, nil
+	}
+
+	organizationIDs, err := o.index.snapshot(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	unattributedWorkspaces, err := listUnattributedWorkspaces(ctx, o.client, organizationIDs)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// Unattributed workspaces that are filtered out don't need the synthetic organization.
+	unattributedWorkspaces = slices.DeleteFunc(unattributedWorkspaces, func(workspace *airbyte.WorkspaceResponse) bool {
+		return !o.scope.workspaces.Includes(workspace.ID, workspace.Name)
+	})
+	if !o.scope.organizations.Includes(unattributedOrganizationID, unattributedOrganizationName) {
+		unattributedWorkspaces = nil
+	}
+
+	if len(unattributedWorkspaces) > 0 {
+		workspaceIDs := make([]string, 0, len(unattributedWorkspaces))
+		for _, workspace := range unattributedWorkspaces {
+			workspaceIDs = append(workspaceIDs, workspace.ID)
+		}
+
+		ctxzap.Extract(ctx).Warn(
+			"airbyte-connector: workspaces could not be attributed to an organization",
+			zap.Strings("workspace_ids", workspaceIDs),
+			zap.Bool("listed_at_top_level", o.topLevelUnattributedWorkspaces),
+		)
+
+		if !o.topLevelUnattributedWorkspaces {
+			resource, err := unattributedOrgResource(len(unattributedWorkspaces), parentResourceID)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("failed to create resource for the unattributed organization: %w", err)
+			}
+
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, "", annos, nil
 }
 
 // Entitlements returns a slice of entitlements for possible user roles under organization.
 func (o *orgBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	if resource.Id.Resource == unattributedOrganizationID {
+		return nil, "", nil, nil
+	}
+
 	// Preallocate slice for efficiency
 	entitlements := make([]*v2.Entitlement, 0, len(PublicOrganizationPermissionsTypes))
 
@@ -109,40 +213,182 @@ func (o *orgBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *p
 }
 
 // Grants returns a slice of grants for each user and their set role under organization.
-func (o *orgBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	users, err := o.client.ListUsersByOrganization(ctx, resource.Id.Resource)
+//
+// The organization members are listed together with their permission in paginated bulk requests. Deployments that
+// don't expose the bulk endpoint fall back to looking up the permission of each member concurrently.
+func (o *orgBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	if resource.Id.Resource == unattributedOrganizationID {
+		return nil, "", nil, nil
+	}
+
+	bag, offsetForCurrentPage, err := parsePageToken(pToken, resource.Id)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list users under organization %s: %w", resource.Id.Resource, err)
+		return nil, "", nil, err
 	}
 
-	var rv []*v2.Grant
-	for _, user := range users {
-		// Get the permission type for the user under the organization
-		permissionType, err := o.getOrganizationPermissionType(ctx, user.ID, resource.Id.Resource)
+	var rowOffset uint64
+	if offsetForCurrentPage != "" {
+		rowOffset, err = strconv.ParseUint(offsetForCurrentPage, 10, 64)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("airbyte-connector: failed to get permission type for user %s under organization %s: %w", user.ID, resource.Id.Resource, err)
+			return nil, "", nil, fmt.Errorf("airbyte-connector: invalid page token %q: %w", offsetForCurrentPage, err)
 		}
+	}
 
+	permissionTypes, nextRowOffset, err := o.listOrganizationPermissionTypes(ctx, resource.Id.Resource, rowOffset)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var rv []*v2.Grant
+	for _, userPermission := range permissionTypes {
 		// check for valid roles and skip if not
-		if !slices.Contains(PublicOrganizationPermissionsTypes, permissionType) {
+		if !slices.Contains(PublicOrganizationPermissionsTypes, userPermission.permissionType) {
 			continue
 		}
 
-		userResource, err := userResource(user)
+		rv = append(rv, grant.NewGrant(resource, userPermission.permissionType, &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     userPermission.userID,
+		}))
+	}
+
+	var offsetForNextPage string
+	if nextRowOffset != 0 {
+		offsetForNextPage = strconv.FormatUint(nextRowOffset, 10)
+	}
+
+	next, err := bag.NextToken(offsetForNextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, next, rateLimitAnnotations(o.client), nil
+}
+
+// Grant assigns an organization role to a user.
+// Airbyte allows only one organization-scoped permission per user, so an existing role is replaced by the granted one.
+func (o *orgBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"airbyte-connector: only users can be granted organization roles",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, nil, fmt.Errorf("airbyte-connector: only users can be granted organization roles")
+	}
+
+	permissionType := entitlement.Slug
+	if !slices.Contains(PublicOrganizationPermissionsTypes, permissionType) {
+		return nil, nil, fmt.Errorf("airbyte-connector: invalid organization permission type %s", permissionType)
+	}
+
+	organizationID := entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	// Permissions are read with a GET, which the HTTP client caches, so an earlier grant or revoke could be missed.
+	clearHTTPCaches(ctx)
+
+	existingPermission, err := o.getOrganizationPermission(ctx, userID, organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var annos annotations.Annotations
+	switch {
+	case existingPermission == nil:
+		_, err = o.client.CreateOrganizationPermission(ctx, userID, organizationID, permissionType)
 		if err != nil {
-			return nil, "", nil, err
+			return nil, nil, fmt.Errorf("airbyte-connector: failed to create permission %s for user %s in organization %s: %w", permissionType, userID, organizationID, err)
 		}
 
-		rv = append(rv, grant.NewGrant(resource, permissionType, userResource.Id))
+	case strings.ToLower(existingPermission.PermissionType) == permissionType:
+		l.Debug(
+			"airbyte-connector: user already has the organization role",
+			zap.String("user_id", userID),
+			zap.String("organization_id", organizationID),
+			zap.String("permission_type", permissionType),
+		)
+		annos.Update(&v2.GrantAlreadyExists{})
+
+	default:
+		_, err = o.client.UpdatePermission(ctx, existingPermission.ID, permissionType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("airbyte-connector: failed to update permission %s for user %s in organization %s: %w", existingPermission.ID, userID, organizationID, err)
+		}
 	}
 
-	return rv, "", nil, nil
+	return []*v2.Grant{grant.NewGrant(entitlement.Resource, permissionType, principal.Id)}, annos, nil
 }
 
-func newOrgBuilder(client *airbyte.Client) *orgBuilder {
+// Revoke removes an organization role from a user.
+// Revoking organization_member removes the user from the organization entirely. Revoking any other role demotes the
+// user to organization_member, since the user still belongs to the organization.
+func (o *orgBuilder) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	principal := g.Principal
+	entitlement := g.Entitlement
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"airbyte-connector: only users can have organization roles revoked",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("airbyte-connector: only users can have organization roles revoked")
+	}
+
+	permissionType := entitlement.Slug
+	organizationID := entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	// Permissions are read with a GET, which the HTTP client caches, so an earlier grant or revoke could be missed.
+	clearHTTPCaches(ctx)
+
+	existingPermission, err := o.getOrganizationPermission(ctx, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var annos annotations.Annotations
+	if existingPermission == nil || strings.ToLower(existingPermission.PermissionType) != permissionType {
+		annos.Update(&v2.GrantAlreadyRevoked{})
+		return annos, nil
+	}
+
+	if permissionType == OrganizationMember {
+		err = o.removeUserFromOrganization(ctx, userID, organizationID)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	_, err = o.client.UpdatePermission(ctx, existingPermission.ID, OrganizationMember)
+	if err != nil {
+		return nil, fmt.Errorf("airbyte-connector: failed to update permission %s for user %s in organization %s: %w", existingPermission.ID, userID, organizationID, err)
+	}
+
+	return nil, nil
+}
+
+func newOrgBuilder(
+	client *airbyte.Client,
+	index *workspaceIndex,
+	scope *syncScope,
+	capabilities *capabilities,
+	topLevelUnattributedWorkspaces bool,
+) *orgBuilder {
 	return &orgBuilder{
-		resourceType: organizationResourceType,
-		client:       client,
+		resourceType:                   organizationResourceType,
+		client:                         client,
+		index:                          index,
+		scope:                          scope,
+		capabilities:                   capabilities,
+		topLevelUnattributedWorkspaces: topLevelUnattributedWorkspaces,
 	}
 }
 
@@ -150,22 +396,149 @@ func newOrgBuilder(client *airbyte.Client) *orgBuilder {
 // PRIVATE HELPER FUNCTIONS
 // -------------------------------------------------------------------------------------------------
 
+// userPermissionType is the organization permission type held by a user.
+type userPermissionType struct {
+	userID         string
+	permissionType string
+}
+
+// listOrganizationPermissionTypes returns the permission types of a page of organization members and the row offset
+// of the next page, 0 if there are no more pages.
+func (o *orgBuilder) listOrganizationPermissionTypes(ctx context.Context, organizationID string, rowOffset uint64) ([]userPermissionType, uint64, error) {
+	l := ctxzap.Extract(ctx)
+
+	users, nextRowOffset, err := o.client.ListOrganizationUsersWithPermissions(ctx, organizationID, ResourcesPageSize, rowOffset)
+	switch status.Code(err) {
+	case codes.OK:
+		permissionTypes := make([]userPermissionType, 0, len(users))
+		for _, user := range users {
+			permissionTypes = append(permissionTypes, userPermissionType{
+				userID:         user.UserID,
+				permissionType: strings.ToLower(user.PermissionType),
+			})
+		}
+
+		return permissionTypes, nextRowOffset, nil
+
+	case codes.NotFound, codes.Unimplemented, codes.PermissionDenied:
+		l.Debug(
+			"airbyte-connector: organization permissions can't be listed in bulk, looking them up per user",
+			zap.String("organization_id", organizationID),
+			zap.Error(err),
+		)
+		return o.lookupOrganizationPermissionTypes(ctx, organizationID, rowOffset)
+
+	default:
+		return nil, 0, fmt.Errorf("airbyte-connector: failed to list users under organization %s: %w", organizationID, err)
+	}
+}
+
+// lookupOrganizationPermissionTypes returns the permission types of a page of organization members, looking up the
+// permission of each member with a bounded number of concurrent requests.
+func (o *orgBuilder) lookupOrganizationPermissionTypes(ctx context.Context, organizationID string, rowOffset uint64) ([]userPermissionType, uint64, error) {
+	// The users endpoint doesn't support pagination, the page is cut from the full list of members.
+	users, err := o.client.ListUsersByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("airbyte-connector: failed to list users under organization %s: %w", organizationID, err)
+	}
+
+	if rowOffset >= uint64(len(users)) {
+		return nil, 0, nil
+	}
+
+	var nextRowOffset uint64
+	users = users[rowOffset:]
+	if uint64(len(users)) > ResourcesPageSize {
+		users = users[:ResourcesPageSize]
+		nextRowOffset = rowOffset + ResourcesPageSize
+	}
+
+	permissionTypes := make([]userPermissionType, len(users))
+	err = forEachConcurrently(ctx, len(users), func(ctx context.Context, i int) error {
+		user := users[i]
+
+		permissionType, err := o.getOrganizationPermissionType(ctx, user.ID, organizationID)
+		if err != nil {
+			return fmt.Errorf("airbyte-connector: failed to get permission type for user %s under organization %s: %w", user.ID, organizationID, err)
+		}
+
+		permissionTypes[i] = userPermissionType{
+			userID:         user.ID,
+			permissionType: permissionType,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return permissionTypes, nextRowOffset, nil
+}
+
 func (o *orgBuilder) getOrganizationPermissionType(ctx context.Context, userID, organizationID string) (string, error) {
-	var allPermissions []*airbyte.Permission
+	permission, err := o.getOrganizationPermission(ctx, userID, organizationID)
+	if err != nil {
+		return "", err
+	}
+
+	if permission == nil {
+		return "", nil
+	}
+
+	return strings.ToLower(permission.PermissionType), nil
+}
 
+// getOrganizationPermission returns the organization-scoped permission the user holds in the organization, or nil if
+// the user is not a member of it.
+func (o *orgBuilder) getOrganizationPermission(ctx context.Context, userID, organizationID string) (*airbyte.Permission, error) {
 	permissions, err := o.client.ListPermissionsByUserAndOrganization(ctx, userID, organizationID)
 	if err != nil {
-		return "", fmt.Errorf("airbyte-connector: failed to list permissions for user %s: %w", userID, err)
+		return nil, fmt.Errorf("airbyte-connector: failed to list permissions for user %s: %w", userID, err)
 	}
 
-	allPermissions = append(allPermissions, permissions...)
-
 	// Find permission for this organization
-	for _, permission := range allPermissions {
-		if permission.Scope == "organization" && permission.ScopeID == organizationID {
-			return strings.ToLower(permission.PermissionType), nil
+	for _, permission := range permissions {
+		if permission.Scope == airbyte.PermissionScopeOrganization && permission.ScopeID == organizationID {
+			return permission, nil
 		}
 	}
 
-	return "", nil
+	return nil, nil
+}
+
+// removeUserFromOrganization deletes every permission the user holds in the organization, including the permissions
+// on workspaces that belong to it, so the user is no longer a member of the organization.
+func (o *orgBuilder) removeUserFromOrganization(ctx context.Context, userID, organizationID string) error {
+	permissions, err := o.client.ListPermissionsByUserAndOrganization(ctx, userID, organizationID)
+	if err != nil {
+		return fmt.Errorf("airbyte-connector: failed to list permissions for user %s: %w", userID, err)
+	}
+
+	// Workspace permissions are removed first so the user never keeps workspace access without an organization role.
+	var organizationPermission *airbyte.Permission
+	for _, permission := range permissions {
+		if permission.Scope == airbyte.PermissionScopeOrganization {
+			if permission.ScopeID == organizationID {
+				organizationPermission = permission
+			}
+			continue
+		}
+
+		err := o.client.DeletePermission(ctx, permission.ID)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return fmt.Errorf("airbyte-connector: failed to delete permission %s for user %s: %w", permission.ID, userID, err)
+		}
+	}
+
+	if organizationPermission == nil {
+		return nil
+	}
+
+	err = o.client.DeletePermission(ctx, organizationPermission.ID)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("airbyte-connector: failed to delete permission %s for user %s: %w", organizationPermission.ID, userID, err)
+	}
+
+	return nil
 }
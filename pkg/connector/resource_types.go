@@ -11,6 +11,13 @@ var userResourceType = &v2.ResourceType{
 	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
 }
 
+// The instance resource type is the root of the resource graph, one Airbyte deployment.
+var instanceResourceType = &v2.ResourceType{
+	Id:          "instance",
+	DisplayName: "Instance",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
 var organizationResourceType = &v2.ResourceType{
 	Id:          "organization",
 	DisplayName: "Organization",
@@ -19,4 +26,37 @@ var organizationResourceType = &v2.ResourceType{
 var workspaceResourceType = &v2.ResourceType{
 	Id:          "workspace",
 	DisplayName: "Workspace",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
+// The invitation resource type is for invitations that weren't accepted yet. Invited users aren't Airbyte users until
+// they accept, so invitations are synced as secrets that expire rather than as users.
+var invitationResourceType = &v2.ResourceType{
+	Id:          "invitation",
+	DisplayName: "Invitation",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+}
+
+var applicationResourceType = &v2.ResourceType{
+	Id:          "application",
+	DisplayName: "Application",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+}
+
+var connectionResourceType = &v2.ResourceType{
+	Id:          "connection",
+	DisplayName: "Connection",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
+var sourceResourceType = &v2.ResourceType{
+	Id:          "source",
+	DisplayName: "Source",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
+var destinationResourceType = &v2.ResourceType{
+	Id:          "destination",
+	DisplayName: "Destination",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
 }
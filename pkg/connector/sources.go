@@ -0,0 +1,102 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+type sourceBuilder struct {
+	resourceType *v2.ResourceType
+	client       *airbyte.Client
+}
+
+func (o *sourceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return sourceResourceType
+}
+
+// Create a new connector resource for an Airbyte source.
+func sourceResource(source *airbyte.SourceResponse, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"source_id":     source.ID,
+		"name":          source.Name,
+		"source_type":   source.SourceType,
+		"definition_id": source.DefinitionID,
+		"workspace_id":  source.WorkspaceID,
+	}
+
+	if source.CreatedAt > 0 {
+		profile["created_at"] = source.CreatedAt
+	}
+
+	resource, err := rs.NewAppResource(
+		source.Name,
+		sourceResourceType,
+		source.ID,
+		[]rs.AppTraitOption{rs.WithAppProfile(profile)},
+		rs.WithParentResourceID(parentResourceID),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns the sources of the parent workspace.
+func (o *sourceBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	// pToken.Token is the offset for the current page
+	bag, offsetForCurrentPage, err := parsePageToken(pToken, &v2.ResourceId{ResourceType: sourceResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	sources, offsetForNextPage, err := o.client.ListSourcesByWorkspace(ctx, parentResourceID.Resource, ResourcesPageSize, offsetForCurrentPage)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list sources under workspace %s: %w", parentResourceID.Resource, err)
+	}
+
+	next, err := bag.NextToken(offsetForNextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resources := make([]*v2.Resource, 0, len(sources))
+	for _, source := range sources {
+		resource, err := sourceResource(source, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource for source %s: %w", source.Name, err)
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, next, rateLimitAnnotations(o.client), nil
+}
+
+// Entitlements always returns an empty slice for sources.
+func (o *sourceBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for sources since they don't have any entitlements.
+func (o *sourceBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newSourceBuilder(client *airbyte.Client) *sourceBuilder {
+	return &sourceBuilder{
+		resourceType: sourceResourceType,
+		client:       client,
+	}
+}
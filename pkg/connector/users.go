@@ -2,18 +2,31 @@ package connector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-airbyte/pkg/airbyte"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type userBuilder struct {
 	resourceType *v2.ResourceType
 	client       *airbyte.Client
+	index        *workspaceIndex
+	scope        *syncScope
+	capabilities *capabilities
 }
 
 func (o *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -21,17 +34,47 @@ func (o *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 }
 
 // Create a new connector resource for an Airbyte user.
-func userResource(user *airbyte.User) (*v2.Resource, error) {
+// The details of the private users API, when known, tell whether the user accepted their invitation or was disabled,
+// so pending and disabled users aren't reported as active.
+func userResource(user *airbyte.User, details *airbyte.UserReadResponse) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"name":  user.Name,
 		"email": user.Email,
 	}
 
+	userStatus := rs.WithStatus(v2.UserTrait_Status_STATUS_ENABLED)
+	var timestampOptions []rs.UserTraitOption
+
+	if details != nil {
+		profile["status"] = details.Status
+		profile["auth_provider"] = details.AuthProvider
+		profile["auth_user_id"] = details.AuthUserID
+		profile["default_workspace_id"] = details.DefaultWorkspaceID
+		profile["company_name"] = details.CompanyName
+		profile["pending_invitation"] = details.Status == airbyte.UserStatusInvited
+
+		switch details.Status {
+		case airbyte.UserStatusInvited:
+			userStatus = rs.WithDetailedStatus(v2.UserTrait_Status_STATUS_DISABLED, "invitation pending")
+		case airbyte.UserStatusDisabled:
+			userStatus = rs.WithStatus(v2.UserTrait_Status_STATUS_DISABLED)
+		}
+
+		if details.CreatedAt > 0 {
+			timestampOptions = append(timestampOptions, rs.WithCreatedAt(time.Unix(details.CreatedAt, 0)))
+		}
+		if details.LastLoginAt > 0 {
+			timestampOptions = append(timestampOptions, rs.WithLastLogin(time.Unix(details.LastLoginAt, 0)))
+		}
+	}
+
 	userTraitOptions := []rs.UserTraitOption{
 		rs.WithUserProfile(profile),
-		rs.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
+		userStatus,
 		rs.WithEmail(user.Email, true),
+		rs.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_HUMAN),
 	}
+	userTraitOptions = append(userTraitOptions, timestampOptions...)
 
 	resource, err := rs.NewUserResource(
 		user.Email,
@@ -47,36 +90,242 @@ func userResource(user *airbyte.User) (*v2.Resource, error) {
 	return resource, nil
 }
 
+// userResources returns the resources of the users, with the details of every user looked up concurrently.
+// Users whose details the application can't read are still synced, without them. Once the deployment refuses to
+// serve the details, e.g. with 401 or 403, the endpoint is recorded as missing and no other user is looked up.
+func (o *userBuilder) userResources(ctx context.Context, users []*airbyte.User) ([]*v2.Resource, error) {
+	details := make([]*airbyte.UserReadResponse, len(users))
+	err := forEachConcurrently(ctx, len(users), func(ctx context.Context, i int) error {
+		if !o.capabilities.has(capabilityUserDetails) {
+			return nil
+		}
+
+		userDetails, err := o.client.GetUser(ctx, users[i].ID)
+		if err != nil {
+			switch {
+			case status.Code(err) == codes.NotFound:
+				ctxzap.Extract(ctx).Debug(
+					"airbyte-connector: user details are not available",
+					zap.String("user_id", users[i].ID),
+					zap.Error(err),
+				)
+				return nil
+			case isUnsupported(err):
+				ctxzap.Extract(ctx).Warn(
+					"airbyte-connector: user details are not served to the application, users are synced without them",
+					zap.Error(err),
+				)
+				o.capabilities.setMissing(capabilityUserDetails, err.Error())
+				return nil
+			default:
+				return fmt.Errorf("airbyte-connector: failed to get user %s: %w", users[i].ID, err)
+			}
+		}
+
+		details[i] = userDetails
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*v2.Resource, 0, len(users))
+	for i, user := range users {
+		ur, err := userResource(user, details[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resource for user %s: %w", user.Email, err)
+		}
+		resources = append(resources, ur)
+	}
+
+	return resources, nil
+}
+
 // List returns all the users as resource objects.
+//
+// Users are listed from the members of every organization first, then from the access information of every
+// workspace, so users with access to a single workspace, or only through an organization the application can't
+// read, aren't lost. Instance administrators are listed last, since they don't need to belong to any organization.
+// Users are deduplicated by ID across organizations, workspaces and instance administrators.
+// Only organizations and workspaces in the sync scope are considered, so users of filtered ones aren't listed.
+//
+// The users are collected in a single call, so the users already listed are tracked in memory instead of being
+// carried in a page token that would grow with every user.
 func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
-	if parentResourceID == nil {
+	if parentResourceID != nil {
 		return nil, "", nil, nil
 	}
 
-	// The only way found to list all users was the list users endpoint with access information per workspace, since the list users endpoint does not work as we might expect..
-	// If we use the endpoint to list users by organization, we would lose the users who only have access to a single workspace.
-	ListUserResponse, err := o.client.ListUsersWithAccessInfoByWorkspace(ctx, parentResourceID.Resource)
+	seen := make(map[string]bool)
+
+	users, err := o.listOrganizationUsers(ctx, seen)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var annos annotations.Annotations
+	if o.capabilities.has(capabilityWorkspaceAccessInfo) {
+		workspaceUsers, err := o.listWorkspaceUsers(ctx, seen)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		users = append(users, workspaceUsers...)
+	} else {
+		annos.Append(skippedAnnotation(capabilityWorkspaceAccessInfo, "listing users without an organization role"))
+	}
+
+	admins, err := o.listInstanceAdmins(ctx, seen)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	users = append(users, admins...)
+
+	resources, err := o.userResources(ctx, users)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list users: %w", err)
+		return nil, "", nil, err
+	}
+
+	if !o.capabilities.has(capabilityUserDetails) {
+		annos.Append(skippedAnnotation(capabilityUserDetails, "reading the status, authentication provider and timestamps of users"))
 	}
 
-	resources := make([]*v2.Resource, 0, len(ListUserResponse))
-	// Convert users to resources
-	for _, userResponse := range ListUserResponse {
-		user := airbyte.User{
-			ID:    userResponse.UserID,
-			Email: userResponse.UserEmail,
-			Name:  userResponse.UserName,
+	annos.Merge(rateLimitAnnotations(o.client)...)
+
+	return resources, "", annos, nil
+}
+
+// listOrganizationUsers returns the members of every organization in the sync scope that weren't seen yet.
+func (o *userBuilder) listOrganizationUsers(ctx context.Context, seen map[string]bool) ([]*airbyte.User, error) {
+	orgs, err := o.client.ListOrganizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("airbyte-connector: failed to list organizations: %w", err)
+	}
+
+	var users []*airbyte.User
+	for _, org := range orgs {
+		if !o.scope.organizations.Includes(org.ID, org.Name) {
+			continue
 		}
-		ur, err := userResource(&user)
 
+		members, err := o.client.ListUsersByOrganization(ctx, org.ID)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("failed to create resource for user %s: %w", user.Email, err)
+			if status.Code(err) != codes.PermissionDenied {
+				return nil, fmt.Errorf("airbyte-connector: failed to list users under organization %s: %w", org.ID, err)
+			}
+			// Members of organizations the application can't read are still found through their workspaces.
+			ctxzap.Extract(ctx).Warn(
+				"airbyte-connector: no access to the users of organization",
+				zap.String("organization_id", org.ID),
+				zap.Error(err),
+			)
+			continue
 		}
-		resources = append(resources, ur)
+
+		for _, member := range members {
+			if seen[member.ID] {
+				continue
+			}
+			seen[member.ID] = true
+			users = append(users, member)
+		}
+	}
+
+	return users, nil
+}
+
+// listWorkspaceUsers returns the users that have access to a workspace in the sync scope and weren't seen yet.
+func (o *userBuilder) listWorkspaceUsers(ctx context.Context, seen map[string]bool) ([]*airbyte.User, error) {
+	organizationIDs, err := o.index.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*airbyte.User
+	offset := ""
+	for {
+		workspaces, nextOffset, err := o.client.ListAllWorkspaces(ctx, ResourcesPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("airbyte-connector: failed to list workspaces: %w", err)
+		}
+
+		for _, workspace := range workspaces {
+			included, err := o.scope.includesWorkspace(ctx, workspace.ID, workspace.Name, organizationIDs[workspace.ID])
+			if err != nil {
+				return nil, err
+			}
+			if !included {
+				continue
+			}
+
+			usersWithAccess, err := o.client.ListUsersWithAccessInfoByWorkspace(ctx, workspace.ID)
+			if err != nil {
+				if status.Code(err) == codes.PermissionDenied {
+					ctxzap.Extract(ctx).Warn(
+						"airbyte-connector: no access to the users of workspace",
+						zap.String("workspace_id", workspace.ID),
+						zap.Error(err),
+					)
+					continue
+				}
+				return nil, fmt.Errorf("airbyte-connector: failed to list users under workspace %s: %w", workspace.ID, err)
+			}
+
+			// Users holding a role of an organization that was listed are already seen. Users whose organization
+			// couldn't be listed still get a workspace grant, e.g. an inherited one, so they are listed here.
+			for _, userResponse := range usersWithAccess {
+				if seen[userResponse.UserID] {
+					continue
+				}
+				seen[userResponse.UserID] = true
+
+				users = append(users, &airbyte.User{
+					ID:    userResponse.UserID,
+					Email: userResponse.UserEmail,
+					Name:  userResponse.UserName,
+				})
+			}
+		}
+
+		if nextOffset == "" || nextOffset == offset {
+			break
+		}
+		offset = nextOffset
 	}
 
-	return resources, "", nil, nil
+	return users, nil
+}
+
+// listInstanceAdmins returns the instance administrators that weren't seen yet. Like their instance_admin grants, they
+// are listed regardless of the filters. Deployments without instance administrators, e.g. Airbyte Cloud, are skipped.
+func (o *userBuilder) listInstanceAdmins(ctx context.Context, seen map[string]bool) ([]*airbyte.User, error) {
+	if !o.capabilities.has(capabilityInstanceConfiguration) {
+		return nil, nil
+	}
+
+	admins, err := o.client.ListInstanceAdmins(ctx)
+	if err != nil {
+		if isUnsupported(err) {
+			ctxzap.Extract(ctx).Debug("airbyte-connector: instance administrators are not available", zap.Error(err))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("airbyte-connector: failed to list instance administrators: %w", err)
+	}
+
+	var users []*airbyte.User
+	for _, admin := range admins {
+		if seen[admin.UserID] {
+			continue
+		}
+		seen[admin.UserID] = true
+
+		users = append(users, &airbyte.User{
+			ID:    admin.UserID,
+			Email: admin.Email,
+			Name:  admin.Name,
+		})
+	}
+
+	return users, nil
 }
 
 // Entitlements always returns an empty slice for users.
@@ -89,9 +338,310 @@ func (o *userBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken
 	return nil, "", nil, nil
 }
 
-func newUserBuilder(client *airbyte.Client) *userBuilder {
+// CreateAccount invites the email address into the chosen organization, or into a workspace of it when a workspace
+// ID is provided, with the requested initial role.
+// Airbyte users sign in through SSO or the emailed invitation, so no credentials are ever returned.
+func (o *userBuilder) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	_ *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	profile := accountInfo.GetProfile()
+
+	email, ok := rs.GetProfileStringValue(profile, "email")
+	if !ok || email == "" {
+		return nil, nil, nil, fmt.Errorf("airbyte-connector: email is required to create an account")
+	}
+
+	organizationID, ok := rs.GetProfileStringValue(profile, "organization_id")
+	if !ok || organizationID == "" {
+		return nil, nil, nil, fmt.Errorf("airbyte-connector: organization_id is required to create an account")
+	}
+
+	workspaceID, _ := rs.GetProfileStringValue(profile, "workspace_id")
+	permissionType, _ := rs.GetProfileStringValue(profile, "permission_type")
+
+	scopeType, scopeID := airbyte.PermissionScopeOrganization, organizationID
+	if workspaceID != "" {
+		scopeType, scopeID = airbyte.PermissionScopeWorkspace, workspaceID
+		if permissionType == "" {
+			permissionType = WorkspaceReader
+		}
+		if !slices.Contains(PublicWorkspacePermissionsTypes, permissionType) {
+			return nil, nil, nil, fmt.Errorf("airbyte-connector: invalid workspace permission type %s", permissionType)
+		}
+	} else {
+		if permissionType == "" {
+			permissionType = OrganizationMember
+		}
+		if !slices.Contains(PublicOrganizationPermissionsTypes, permissionType) {
+			return nil, nil, nil, fmt.Errorf("airbyte-connector: invalid organization permission type %s", permissionType)
+		}
+	}
+
+	invitation, err := o.client.CreateUserInvitation(ctx, email, scopeType, scopeID, permissionType)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("airbyte-connector: failed to invite %s to %s %s: %w", email, scopeType, scopeID, err)
+	}
+
+	if !invitation.DirectlyAdded {
+		l.Debug(
+			"airbyte-connector: user invited",
+			zap.String("email", email),
+			zap.String("scope_type", scopeType),
+			zap.String("scope_id", scopeID),
+		)
+
+		return &v2.CreateAccountResponse_ActionRequiredResult{
+			Message:               fmt.Sprintf("An invitation was sent to %s. The account is created once the invitation is accepted.", email),
+			IsCreateAccountResult: true,
+		}, nil, nil, nil
+	}
+
+	// The user already had an Airbyte account and was granted access directly. Members are read with a GET, which the
+	// HTTP client caches, so a user added since the cache filled could be missed.
+	clearHTTPCaches(ctx)
+
+	user, err := o.findOrganizationUserByEmail(ctx, organizationID, email)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if user == nil {
+		return &v2.CreateAccountResponse_ActionRequiredResult{
+			Message:               fmt.Sprintf("%s already has an Airbyte account and was granted access to %s %s.", email, scopeType, scopeID),
+			IsCreateAccountResult: false,
+		}, nil, nil, nil
+	}
+
+	resource, err := userResource(user, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{
+		Resource:              resource,
+		IsCreateAccountResult: false,
+	}, nil, nil, nil
+}
+
+// CreateAccountCapabilityDetails advertises that accounts are provisioned without a password.
+func (o *userBuilder) CreateAccountCapabilityDetails(_ context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return &v2.CredentialDetailsAccountProvisioning{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+	}, nil, nil
+}
+
+// Create is not supported for users, accounts are provisioned through CreateAccount instead.
+func (o *userBuilder) Create(_ context.Context, _ *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	return nil, nil, status.Error(codes.Unimplemented, "airbyte-connector: users are created through account provisioning")
+}
+
+// Delete deprovisions a user by removing every organization and workspace permission the user holds.
+// Airbyte has no endpoint to delete a user account, so a user without any permission is considered removed.
+// After the removal pass, the permissions are listed again and any that remain are reported in the returned error.
+// Organizations and workspaces the application couldn't read are skipped instead of aborting. When every permission
+// that could be seen was removed, the deletion succeeds with an annotation listing those inaccessible scopes.
+func (o *userBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if resourceId.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("airbyte-connector: unsupported resource type %s", resourceId.ResourceType)
+	}
+
+	userID := resourceId.Resource
+
+	permissions, inaccessibleScopes, err := o.listUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Workspace permissions are removed before organization permissions so the user never keeps workspace access
+	// without an organization role.
+	slices.SortStableFunc(permissions, func(a, b *airbyte.Permission) int {
+		return strings.Compare(b.Scope, a.Scope)
+	})
+
+	var errs []error
+	for _, permission := range permissions {
+		err := o.client.DeletePermission(ctx, permission.ID)
+		if err != nil && status.Code(err) != codes.NotFound {
+			errs = append(errs, fmt.Errorf("failed to delete %s permission %s on %s: %w", permission.Scope, permission.ID, permission.ScopeID, err))
+			continue
+		}
+
+		l.Debug(
+			"airbyte-connector: permission deleted",
+			zap.String("user_id", userID),
+			zap.String("permission_id", permission.ID),
+			zap.String("scope", permission.Scope),
+			zap.String("scope_id", permission.ScopeID),
+		)
+	}
+
+	// GET responses are cached by the HTTP client, so the cache is cleared to verify against the current state.
+	clearHTTPCaches(ctx)
+
+	remaining, remainingInaccessibleScopes, err := o.listUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("airbyte-connector: failed to verify removal of user %s: %w", userID, err)
+	}
+
+	if len(remaining) > 0 {
+		remainingPermissions := make([]string, 0, len(remaining))
+		for _, permission := range remaining {
+			remainingPermissions = append(remainingPermissions, fmt.Sprintf("%s %s on %s %s", permission.PermissionType, permission.ID, permission.Scope, permission.ScopeID))
+		}
+
+		remainingErr := fmt.Errorf("airbyte-connector: user %s still holds %d permissions: %s", userID, len(remaining), strings.Join(remainingPermissions, ", "))
+		return nil, errors.Join(append([]error{remainingErr}, errs...)...)
+	}
+
+	// Scopes the application can't read may still hold permissions of the user. Every permission that could be seen is
+	// gone, so the deletion succeeds, and the scopes are reported so a partial deprovision can be told apart.
+	inaccessibleScopes = append(inaccessibleScopes, remainingInaccessibleScopes...)
+	slices.Sort(inaccessibleScopes)
+	inaccessibleScopes = slices.Compact(inaccessibleScopes)
+	if len(inaccessibleScopes) > 0 {
+		l.Warn(
+			"airbyte-connector: permissions of user could not be checked in inaccessible scopes",
+			zap.String("user_id", userID),
+			zap.Strings("inaccessible_scopes", inaccessibleScopes),
+		)
+
+		var annos annotations.Annotations
+		annos.Append(inaccessibleScopesAnnotation(inaccessibleScopes))
+		return annos, nil
+	}
+
+	return nil, nil
+}
+
+// inaccessibleScopesAnnotation reports the organizations and workspaces a user's permissions couldn't be checked in.
+func inaccessibleScopesAnnotation(scopes []string) *structpb.Struct {
+	values := make([]*structpb.Value, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, structpb.NewStringValue(scope))
+	}
+
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"inaccessible_scopes": structpb.NewListValue(&structpb.ListValue{Values: values}),
+		},
+	}
+}
+
+func newUserBuilder(client *airbyte.Client, index *workspaceIndex, scope *syncScope, capabilities *capabilities) *userBuilder {
 	return &userBuilder{
 		resourceType: userResourceType,
 		client:       client,
+		index:        index,
+		scope:        scope,
+		capabilities: capabilities,
+	}
+}
+
+// -------------------------------------------------------------------------------------------------
+// PRIVATE HELPER FUNCTIONS
+// -------------------------------------------------------------------------------------------------
+
+// findOrganizationUserByEmail returns the organization member with the given email, or nil if there is none.
+func (o *userBuilder) findOrganizationUserByEmail(ctx context.Context, organizationID, email string) (*airbyte.User, error) {
+	users, err := o.client.ListUsersByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("airbyte-connector: failed to list users under organization %s: %w", organizationID, err)
 	}
+
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// listUserPermissions returns every permission the user holds, deduplicated by permission ID.
+// Organization and workspace permissions inside accessible organizations come from the permissions API, while
+// workspace permissions in any other visible workspace are read from the workspace access info.
+//
+// Organizations and workspaces the application isn't allowed to read are skipped and returned as inaccessible scopes,
+// e.g. "workspace 1234", so the permissions that can be found are still removed.
+func (o *userBuilder) listUserPermissions(ctx context.Context, userID string) ([]*airbyte.Permission, []string, error) {
+	permissionsByID := make(map[string]*airbyte.Permission)
+	var inaccessibleScopes []string
+
+	orgs, err := o.client.ListOrganizations(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("airbyte-connector: failed to list organizations: %w", err)
+	}
+
+	for _, org := range orgs {
+		permissions, err := o.client.ListPermissionsByUserAndOrganization(ctx, userID, org.ID)
+		if err != nil {
+			if status.Code(err) != codes.PermissionDenied {
+				return nil, nil, fmt.Errorf("airbyte-connector: failed to list permissions for user %s under organization %s: %w", userID, org.ID, err)
+			}
+			inaccessibleScopes = append(inaccessibleScopes, fmt.Sprintf("%s %s", airbyte.PermissionScopeOrganization, org.ID))
+			continue
+		}
+
+		for _, permission := range permissions {
+			if permission.UserID != "" && permission.UserID != userID {
+				continue
+			}
+			permissionsByID[permission.ID] = permission
+		}
+	}
+
+	offset := ""
+	for {
+		workspaces, nextOffset, err := o.client.ListAllWorkspaces(ctx, ResourcesPageSize, offset)
+		if err != nil {
+			return nil, nil, fmt.Errorf("airbyte-connector: failed to list workspaces: %w", err)
+		}
+
+		for _, workspace := range workspaces {
+			usersWithAccessInfo, err := o.client.ListUsersWithAccessInfoByWorkspace(ctx, workspace.ID)
+			if err != nil {
+				if status.Code(err) != codes.PermissionDenied {
+					return nil, nil, fmt.Errorf("airbyte-connector: failed to list users under workspace %s: %w", workspace.ID, err)
+				}
+				inaccessibleScopes = append(inaccessibleScopes, fmt.Sprintf("%s %s", airbyte.PermissionScopeWorkspace, workspace.ID))
+				continue
+			}
+
+			for _, userWithAccessInfo := range usersWithAccessInfo {
+				if userWithAccessInfo.UserID != userID || userWithAccessInfo.WorkspacePermission == nil {
+					continue
+				}
+
+				workspacePermission := userWithAccessInfo.WorkspacePermission
+				permissionsByID[workspacePermission.PermissionID] = &airbyte.Permission{
+					ID:             workspacePermission.PermissionID,
+					PermissionType: workspacePermission.PermissionType,
+					UserID:         userID,
+					ScopeID:        workspace.ID,
+					Scope:          airbyte.PermissionScopeWorkspace,
+				}
+			}
+		}
+
+		if nextOffset == "" || nextOffset == offset {
+			break
+		}
+		offset = nextOffset
+	}
+
+	permissions := make([]*airbyte.Permission, 0, len(permissionsByID))
+	for _, permission := range permissionsByID {
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, inaccessibleScopes, nil
 }
@@ -0,0 +1,234 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUserResourceStatus(t *testing.T) {
+	testCases := []struct {
+		message string
+		details *airbyte.UserReadResponse
+		status  v2.UserTrait_Status_Status
+	}{
+		{"no details", nil, v2.UserTrait_Status_STATUS_ENABLED},
+		{"registered", &airbyte.UserReadResponse{Status: airbyte.UserStatusRegistered}, v2.UserTrait_Status_STATUS_ENABLED},
+		{"pending invitation", &airbyte.UserReadResponse{Status: airbyte.UserStatusInvited}, v2.UserTrait_Status_STATUS_DISABLED},
+		{"disabled", &airbyte.UserReadResponse{Status: airbyte.UserStatusDisabled}, v2.UserTrait_Status_STATUS_DISABLED},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			resource, err := userResource(&airbyte.User{ID: "user-1", Email: "jane@example.com"}, tc.details)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			userTrait, err := rs.GetUserTrait(resource)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if userTrait.Status.Status != tc.status {
+				t.Fatalf("expected status %s, got %s", tc.status, userTrait.Status.Status)
+			}
+		})
+	}
+}
+
+func TestUserDeleteWithInaccessibleScopes(t *testing.T) {
+	ctx := context.Background()
+
+	// The user holds a permission in the first organization, the second organization and the workspace can't be read.
+	var mu sync.Mutex
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/public/v1/organizations":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]string{{"organizationId": "org-1"}, {"organizationId": "org-2"}},
+			})
+		case r.URL.Path == "/api/public/v1/permissions" && r.URL.Query().Get("organizationId") == "org-1":
+			permissions := []map[string]string{}
+			if len(deleted) == 0 {
+				permissions = append(permissions, map[string]string{
+					"permissionId":   "permission-1",
+					"permissionType": OrganizationMember,
+					"userId":         "user-1",
+					"scopeId":        "org-1",
+					"scope":          airbyte.PermissionScopeOrganization,
+				})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": permissions})
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/public/v1/permissions/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/public/v1/permissions/"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/api/public/v1/workspaces":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]string{{"workspaceId": "workspace-1"}},
+			})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "forbidden"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := airbyte.NewClient(ctx, server.URL, airbyte.NoAuth())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := newUserBuilder(client, newWorkspaceIndex(client, newCapabilities()), &syncScope{}, newCapabilities())
+	annos, err := builder.Delete(ctx, &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "user-1"})
+	if err != nil {
+		t.Fatalf("expected the deletion to succeed, got %v", err)
+	}
+
+	if len(deleted) != 1 || deleted[0] != "permission-1" {
+		t.Fatalf("expected permission-1 to be deleted, deleted %v", deleted)
+	}
+
+	if len(annos) != 1 {
+		t.Fatalf("expected an annotation listing the inaccessible scopes, got %d annotations", len(annos))
+	}
+	report := &structpb.Struct{}
+	if err := annos[0].UnmarshalTo(report); err != nil {
+		t.Fatal(err)
+	}
+
+	var scopes []string
+	for _, value := range report.Fields["inaccessible_scopes"].GetListValue().GetValues() {
+		scopes = append(scopes, value.GetStringValue())
+	}
+	expected := []string{"organization org-2", "workspace workspace-1"}
+	if strings.Join(scopes, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected inaccessible scopes %v, got %v", expected, scopes)
+	}
+}
+
+func TestUserListDeduplicates(t *testing.T) {
+	ctx := context.Background()
+
+	// jane belongs to both organizations and the workspace and is an instance administrator, john only has access to
+	// the workspace and the other administrator doesn't belong to any organization.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/public/v1/organizations":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]string{{"organizationId": "org-1"}, {"organizationId": "org-2"}},
+			})
+		case "/api/public/v1/users":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]string{{"id": "user-1", "email": "jane@example.com"}},
+			})
+		case "/api/public/v1/workspaces":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]string{{"workspaceId": "workspace-1"}},
+			})
+		case "/api/v1/users/list_access_info_by_workspace_id":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"usersWithAccess": []map[string]string{
+					{"userId": "user-1", "userEmail": "jane@example.com"},
+					{"userId": "user-2", "userEmail": "john@example.com"},
+				},
+			})
+		case "/api/v1/users/list_instance_admins":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"users": []map[string]string{
+					{"userId": "user-1", "email": "jane@example.com"},
+					{"userId": "user-3", "email": "admin@example.com"},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := airbyte.NewClient(ctx, server.URL, airbyte.NoAuth())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caps := newCapabilities()
+	caps.setMissing(capabilityWorkspacesByOrganization, "not found")
+
+	builder := newUserBuilder(client, newWorkspaceIndex(client, caps), newSyncScope(client, nil, nil), caps)
+	resources, next, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next != "" {
+		t.Fatalf("expected a single page, got next page token %q", next)
+	}
+
+	var userIDs []string
+	for _, resource := range resources {
+		userIDs = append(userIDs, resource.Id.Resource)
+	}
+	if strings.Join(userIDs, ",") != "user-1,user-2,user-3" {
+		t.Fatalf("expected each user to be listed once, got %v", userIDs)
+	}
+}
+
+func TestUserResourcesStopAtRefusedDetails(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var lookups int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lookups++
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, err := airbyte.NewClient(ctx, server.URL, airbyte.NoAuth())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := make([]*airbyte.User, 100)
+	for i := range users {
+		users[i] = &airbyte.User{ID: fmt.Sprintf("user-%d", i), Email: fmt.Sprintf("user-%d@example.com", i)}
+	}
+
+	caps := newCapabilities()
+	builder := newUserBuilder(client, newWorkspaceIndex(client, caps), newSyncScope(client, nil, nil), caps)
+	resources, err := builder.userResources(ctx, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resources) != len(users) {
+		t.Fatalf("expected every user to be synced, got %d resources", len(resources))
+	}
+	if caps.has(capabilityUserDetails) {
+		t.Fatal("expected user details to be recorded as missing")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if lookups > lookupConcurrency {
+		t.Fatalf("expected the lookups to stop at the first refusal, got %d lookups", lookups)
+	}
+}
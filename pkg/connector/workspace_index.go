@@ -0,0 +1,103 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+)
+
+// workspaceIndex maps workspace IDs to the IDs of their organizations.
+//
+// The public workspaces endpoint doesn't include organization IDs, so the index is built from the workspaces of every
+// accessible organization. Workspaces of organizations the application can't access are missing from it.
+//
+// The index is owned by a connector and safe for concurrent use. It is built lazily, e.g. when a sync resumes in a new
+// process, and rebuilt at the start of every sync by the organization builder.
+type workspaceIndex struct {
+	client       *airbyte.Client
+	capabilities *capabilities
+
+	mu sync.Mutex
+	// organizationIDs is nil until the index is built. It is replaced, never modified, so snapshots stay valid.
+	organizationIDs map[string]string
+	// workspaces holds the details of the indexed workspaces, built and replaced together with organizationIDs.
+	workspaces map[string]*airbyte.Workspace
+}
+
+func newWorkspaceIndex(client *airbyte.Client, capabilities *capabilities) *workspaceIndex {
+	return &workspaceIndex{
+		client:       client,
+		capabilities: capabilities,
+	}
+}
+
+// snapshot returns the workspace to organization mapping, building the index if it is missing.
+// The returned map must not be modified.
+func (i *workspaceIndex) snapshot(ctx context.Context) (map[string]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.organizationIDs == nil {
+		err := i.buildLocked(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return i.organizationIDs, nil
+}
+
+// details returns the details of the indexed workspaces, building the index if it is missing.
+// The returned map must not be modified.
+func (i *workspaceIndex) details(ctx context.Context) (map[string]*airbyte.Workspace, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.organizationIDs == nil {
+		err := i.buildLocked(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return i.workspaces, nil
+}
+
+// refresh rebuilds the index, so a new sync doesn't see workspaces moved or created since the previous one.
+func (i *workspaceIndex) refresh(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.buildLocked(ctx)
+}
+
+// buildLocked builds the index. It must be called with i.mu held, concurrent callers wait for the build in flight.
+func (i *workspaceIndex) buildLocked(ctx context.Context) error {
+	// Without the private workspace listing no workspace can be attributed to its organization.
+	if !i.capabilities.has(capabilityWorkspacesByOrganization) {
+		i.organizationIDs = map[string]string{}
+		i.workspaces = map[string]*airbyte.Workspace{}
+		return nil
+	}
+
+	workspaces, err := getAllWorkspacesWithParentOrganizationID(ctx, i.client)
+	if err != nil {
+		return fmt.Errorf("airbyte-connector: failed to build the workspace index: %w", err)
+	}
+
+	organizationIDs := make(map[string]string, len(workspaces))
+	details := make(map[string]*airbyte.Workspace, len(workspaces))
+	for _, workspace := range workspaces {
+		if workspace.OrganizationId != "" {
+			organizationIDs[workspace.ID] = workspace.OrganizationId
+		}
+		details[workspace.ID] = workspace
+	}
+
+	i.organizationIDs = organizationIDs
+	i.workspaces = details
+
+	return nil
+}
@@ -13,6 +13,10 @@ import (
 	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	grant "github.com/conductorone/baton-sdk/pkg/types/grant"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Define workspace permission type constants.
@@ -32,12 +36,14 @@ var PublicWorkspacePermissionsTypes = []string{
 	WorkspaceReader,
 }
 
-// workspacesWithOrgIDMap maps workspace IDs to their corresponding organization IDs.
-var workspacesWithOrgIDMap map[string]string
-
 type workspaceBuilder struct {
-	resourceType *v2.ResourceType
-	client       *airbyte.Client
+	resourceType                   *v2.ResourceType
+	client                         *airbyte.Client
+	index                          *workspaceIndex
+	scope                          *syncScope
+	capabilities                   *capabilities
+	topLevelUnattributedWorkspaces bool
+	skipTombstonedWorkspaces       bool
 }
 
 func (o *workspaceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -45,14 +51,67 @@ func (o *workspaceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 }
 
 // Create a new connector resource for an airbyte workspace.
-func workspaceResource(workspace airbyte.Workspace, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
-	resource, err := rs.NewResource(
+// The profile records where the workspace processes data and how it notifies about syncs. The details of the private
+// workspace API, e.g. the slug and tombstone state, are only set for workspaces of accessible organizations.
+func workspaceResource(
+	workspace *airbyte.WorkspaceResponse,
+	details *airbyte.Workspace,
+	workspaceURL string,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	notifications := workspace.Notifications
+	profile := map[string]interface{}{
+		"workspace_id":   workspace.ID,
+		"name":           workspace.Name,
+		"data_residency": workspace.DataResidency,
+		"notifications_failure": notificationChannels(
+			notifications.Failure.Email, notifications.Failure.Webhook,
+		),
+		"notifications_success": notificationChannels(
+			notifications.Success.Email, notifications.Success.Webhook,
+		),
+		"notifications_connection_update": notificationChannels(
+			notifications.ConnectionUpdate.Email, notifications.ConnectionUpdate.Webhook,
+		),
+		"notifications_connection_update_action_required": notificationChannels(
+			notifications.ConnectionUpdateActionRequired.Email, notifications.ConnectionUpdateActionRequired.Webhook,
+		),
+		"notifications_sync_disabled": notificationChannels(
+			notifications.SyncDisabled.Email, notifications.SyncDisabled.Webhook,
+		),
+		"notifications_sync_disabled_warning": notificationChannels(
+			notifications.SyncDisabledWarning.Email, notifications.SyncDisabledWarning.Webhook,
+		),
+	}
+
+	if details != nil {
+		profile["organization_id"] = details.OrganizationId
+		profile["slug"] = details.Slug
+		profile["default_geography"] = details.DefaultGeography
+		profile["tombstone"] = details.Tombstone
+		profile["webhook_config_count"] = details.WebhookConfigCount
+	}
+
+	resource, err := rs.NewAppResource(
 		workspace.Name,
 		workspaceResourceType,
 		workspace.ID,
+		[]rs.AppTraitOption{rs.WithAppProfile(profile)},
 		rs.WithAnnotation(
 			&v2.ChildResourceType{
-				ResourceTypeId: userResourceType.Id,
+				ResourceTypeId: connectionResourceType.Id,
+			},
+			&v2.ChildResourceType{
+				ResourceTypeId: sourceResourceType.Id,
+			},
+			&v2.ChildResourceType{
+				ResourceTypeId: destinationResourceType.Id,
+			},
+			&v2.ChildResourceType{
+				ResourceTypeId: invitationResourceType.Id,
+			},
+			&v2.ExternalLink{
+				Url: workspaceURL,
 			},
 		),
 		rs.WithParentResourceID(parentResourceID),
@@ -65,6 +124,19 @@ func workspaceResource(workspace airbyte.Workspace, parentResourceID *v2.Resourc
 	return resource, nil
 }
 
+// notificationChannels returns the enabled notification channels of an event, e.g. "email,webhook".
+func notificationChannels(email, webhook airbyte.NotificationSetting) string {
+	var channels []string
+	if email.Enabled {
+		channels = append(channels, "email")
+	}
+	if webhook.Enabled {
+		channels = append(channels, "webhook")
+	}
+
+	return strings.Join(channels, ",")
+}
+
 // List returns all workspaces and their parent organization IDs (when available).
 // The process requires two API calls:
 //  1. GET /api/public/v1/workspaces
@@ -72,32 +144,24 @@ func workspaceResource(workspace airbyte.Workspace, parentResourceID *v2.Resourc
 //  2. GET /api/v1/workspaces/list_by_organization_id
 //     Returns workspaces into the accessible organizations only
 //
-// Workspaces belonging to organizations we can't access will be marked with an
-// "unknown-parent" organization ID.
+// Workspaces belonging to organizations we can't access are parented to the synthetic unattributed organization,
+// or listed as top-level resources when the connector is configured to do so. Workspaces outside of the sync scope
+// are skipped, and so are tombstoned workspaces when the connector is configured to skip them instead of flagging them
+// in their profile.
 func (o *workspaceBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
-	// Initialize the map if we're starting a new list
-	if pToken.Token == "" {
-		// Initialize the map
-		workspacesWithOrgIDMap = make(map[string]string)
-
-		// Get workspaces with organizations
-		allWorkspacesWithParentOrganizationID, err := o.getAllWorkspacesWithParentOrganizationID(ctx)
-		if err != nil {
-			return nil, "", nil, fmt.Errorf("airbyte-connector: getAllWorkspacesWithParentOrganizationID > failed to list workspaces: %w", err)
-		}
+	// pToken.Token is the offset for the current page
+	bag, offsetForCurrentPage, err := parsePageToken(pToken, &v2.ResourceId{ResourceType: workspaceResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
 
-		// Populate the map with workspace-to-organization relationships
-		// The public workspaces endpoint doesn't include organization IDs,
-		// so we maintain this mapping to associate workspaces with their parent organizations
-		for _, w := range allWorkspacesWithParentOrganizationID {
-			if w.OrganizationId != "" {
-				workspacesWithOrgIDMap[w.ID] = w.OrganizationId
-			}
-		}
+	// The index isn't persisted in page tokens, a sync resumed in another process rebuilds it.
+	organizationIDs, err := o.index.snapshot(ctx)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
-	// pToken.Token is the offset for the current page
-	bag, offsetForCurrentPage, err := parsePageToken(pToken, &v2.ResourceId{ResourceType: workspaceResourceType.Id})
+	details, err := o.index.details(ctx)
 	if err != nil {
 		return nil, "", nil, err
 	}
@@ -115,37 +179,62 @@ func (o *workspaceBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *p
 	// Process all workspaces
 	resources := make([]*v2.Resource, 0, len(listWorkspaceResponse))
 	for _, ws := range listWorkspaceResponse {
-		workspace := airbyte.Workspace{
-			ID:   ws.ID,
-			Name: ws.Name,
+		included, err := o.scope.includesWorkspace(ctx, ws.ID, ws.Name, organizationIDs[ws.ID])
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if !included {
+			continue
+		}
+
+		workspaceDetails := details[ws.ID]
+		if workspaceDetails != nil && workspaceDetails.Tombstone && o.skipTombstonedWorkspaces {
+			ctxzap.Extract(ctx).Debug(
+				"airbyte-connector: skipping tombstoned workspace",
+				zap.String("workspace_id", ws.ID),
+				zap.String("workspace_name", ws.Name),
+			)
+			continue
 		}
 
 		var parentResourceID *v2.ResourceId
 		// Only set parent resource ID if we have a valid organization ID
-		if orgID, exists := workspacesWithOrgIDMap[ws.ID]; exists && orgID != "" {
-			workspace.OrganizationId = orgID
+		if orgID, exists := organizationIDs[ws.ID]; exists && orgID != "" {
 			parentResourceID = &v2.ResourceId{
 				ResourceType: organizationResourceType.Id,
 				Resource:     orgID,
 			}
-		} else {
-			// If we don't have a valid organization ID, we set the parent resource ID to "unknown-parent"
-			// This is used to indicate that the workspace is associated with an organization that we don't have access to.
-			parentResourceID = &v2.ResourceId{
-				ResourceType: organizationResourceType.Id,
-				Resource:     "unknown-parent",
+		} else if o.capabilities.has(capabilityWorkspacesByOrganization) {
+			// Without the private workspace listing no workspace is attributed, they are all listed at the top level.
+			// Otherwise the workspace belongs to an organization we don't have access to. The organization builder warns about
+			// these workspaces and emits the synthetic organization they are parented to.
+			ctxzap.Extract(ctx).Debug(
+				"airbyte-connector: workspace not attributed to an organization",
+				zap.String("workspace_id", ws.ID),
+				zap.String("workspace_name", ws.Name),
+			)
+			if !o.topLevelUnattributedWorkspaces {
+				parentResourceID = &v2.ResourceId{
+					ResourceType: organizationResourceType.Id,
+					Resource:     unattributedOrganizationID,
+				}
 			}
 		}
 
-		resource, err := workspaceResource(workspace, parentResourceID)
+		resource, err := workspaceResource(ws, workspaceDetails, o.client.WorkspaceURL(ws.ID), parentResourceID)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("failed to create resource for workspace %s: %w", workspace.Name, err)
+			return nil, "", nil, fmt.Errorf("failed to create resource for workspace %s: %w", ws.Name, err)
 		}
 
 		resources = append(resources, resource)
 	}
 
-	return resources, next, nil, nil
+	annos := rateLimitAnnotations(o.client)
+	if !o.capabilities.has(capabilityWorkspacesByOrganization) && offsetForCurrentPage == "" {
+		annos.Append(skippedAnnotation(capabilityWorkspacesByOrganization, "parenting workspaces to their organization"))
+	}
+
+	return resources, next, annos, nil
 }
 
 // Entitlements returns a slice of entitlements for possible user roles under workspace (Viewer, Editor, Admin).
@@ -159,8 +248,9 @@ func (o *workspaceBuilder) Entitlements(_ context.Context, resource *v2.Resource
 		description := fmt.Sprintf("%s role in %s Airbyte workspace", permissionType, resource.DisplayName)
 
 		// Define entitlement options
+		// Organizations also hold these roles through expandable grants, which don't need to be grantable.
 		entitlementOptions := []ent.EntitlementOption{
-			ent.WithGrantableTo(workspaceResourceType),
+			ent.WithGrantableTo(userResourceType),
 			ent.WithDisplayName(displayName),
 			ent.WithDescription(description),
 		}
@@ -173,65 +263,206 @@ func (o *workspaceBuilder) Entitlements(_ context.Context, resource *v2.Resource
 }
 
 // Grants returns a slice of grants for each user and their set role under workspace.
+//
+// Only workspace permissions are granted to users directly. Organization roles propagate down to every workspace of
+// the organization, which is modeled with expandable grants of the workspace roles to the organization: the SDK then
+// grants the matching workspace role to every holder of the organization role, with the organization role as source,
+// as an immutable grant that can only be revoked on the organization.
 func (o *workspaceBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	listUserswithaccessInfoResponse, err := o.client.ListUsersWithAccessInfoByWorkspace(ctx, resource.Id.Resource)
+	organizationID, err := o.workspaceOrganizationID(ctx, resource)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list users under workspace %s: %w", resource.Id.Resource, err)
+		return nil, "", nil, err
 	}
 
-	// Map organization permissions to workspace permissions.
-	// We use this mapping because organization permissions propagate down to workspaces.
-	// In some cases, users may only have organization-level permissions set without explicit
-	// workspace permissions. This mapping ensures we correctly reflect the inherited
-	// permissions at the workspace level.
-	orgToWorkspacePermMap := map[string]string{
-		"organization_admin":  WorkspaceAdmin,
-		"organization_editor": WorkspaceEditor,
-		"organization_runner": WorkspaceRunner,
-		"organization_reader": WorkspaceReader,
+	var rv []*v2.Grant
+	if organizationID != "" {
+		rv = append(rv, organizationWorkspaceGrants(resource, organizationID)...)
+	}
+
+	// The roles of users are only known from the private access info listing, without it only the roles inherited
+	// from the organization are granted.
+	if !o.capabilities.has(capabilityWorkspaceAccessInfo) {
+		annos := rateLimitAnnotations(o.client)
+		annos.Append(skippedAnnotation(capabilityWorkspaceAccessInfo, "granting workspace roles to users"))
+		return rv, "", annos, nil
+	}
+
+	listUserswithaccessInfoResponse, err := o.client.ListUsersWithAccessInfoByWorkspace(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("airbyte-connector: failed to list users under workspace %s: %w", resource.Id.Resource, err)
 	}
 
-	var rv []*v2.Grant
 	for _, userResponse := range listUserswithaccessInfoResponse {
-		var permissionType string
+		principalID := &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     userResponse.UserID,
+		}
 
-		// Prefer workspace-level permission over organization-level
+		var permissionType, organizationPermissionType string
 		if userResponse.WorkspacePermission != nil {
 			permissionType = strings.ToLower(userResponse.WorkspacePermission.PermissionType)
-		} else if userResponse.OrganizationPermission != nil {
-			orgPermType := strings.ToLower(userResponse.OrganizationPermission.PermissionType)
-			// Map organization permission to workspace permission
-			if mappedPerm, exists := orgToWorkspacePermMap[orgPermType]; exists {
-				permissionType = mappedPerm
+		}
+		if userResponse.OrganizationPermission != nil {
+			organizationPermissionType = strings.ToLower(userResponse.OrganizationPermission.PermissionType)
+		}
+
+		// The metadata tells reviewers which of the explicit and the inherited role wins.
+		effective := resolveWorkspacePermission(permissionType, organizationPermissionType)
+		metadata := grant.WithGrantMetadata(effective.metadata())
+
+		var inherited *airbyte.PermissionRead
+		var inheritedSourced bool
+		// Roles of organizations that aren't synced can't be expanded, they are granted to the user directly instead.
+		// The organization role is only referenced as source when the organization is synced.
+		if organizationID == "" && userResponse.OrganizationPermission != nil {
+			inherited = userResponse.OrganizationPermission
+			inheritedSourced, err = o.scope.emitsOrganization(ctx, inherited.OrganizationID)
+			if err != nil {
+				return nil, "", nil, err
 			}
 		}
 
-		// Skip if no valid permission type found
-		if !slices.Contains(PublicWorkspacePermissionsTypes, permissionType) && !slices.Contains(PublicOrganizationPermissionsTypes, permissionType) {
-			continue
+		if slices.Contains(PublicWorkspacePermissionsTypes, permissionType) {
+			g := grant.NewGrant(resource, permissionType, principalID, metadata)
+			// A workspace role also granted by the organization has both the workspace and the organization as source.
+			if inherited != nil && inheritedSourced && effective.inherited == permissionType {
+				g.Sources = &v2.GrantSources{
+					Sources: map[string]*v2.GrantSources_GrantSource{
+						g.Entitlement.Id: {},
+						organizationEntitlementID(inherited.OrganizationID, organizationPermissionType): {},
+					},
+				}
+				inherited = nil
+			}
+			rv = append(rv, g)
 		}
 
-		user := airbyte.User{
-			ID:    userResponse.UserID,
-			Email: userResponse.UserEmail,
-			Name:  userResponse.UserName,
+		if inherited != nil {
+			if g := inheritedWorkspaceGrant(resource, principalID, inherited, inheritedSourced, metadata); g != nil {
+				rv = append(rv, g)
+			}
 		}
+	}
+
+	return rv, "", rateLimitAnnotations(o.client), nil
+}
 
-		userResource, err := userResource(&user)
+// Grant assigns a workspace role to a user.
+// Airbyte keeps a single workspace-scoped permission per user, so an existing permission with a different role is
+// updated in place instead of creating a second one.
+func (o *workspaceBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"airbyte-connector: only users can be granted workspace roles",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, nil, fmt.Errorf("airbyte-connector: only users can be granted workspace roles")
+	}
+
+	permissionType := entitlement.Slug
+	if !slices.Contains(PublicWorkspacePermissionsTypes, permissionType) {
+		return nil, nil, fmt.Errorf("airbyte-connector: invalid workspace permission type %s", permissionType)
+	}
+
+	workspaceID := entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	existingPermission, err := o.getWorkspacePermission(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var annos annotations.Annotations
+	switch {
+	case existingPermission == nil:
+		_, err = o.client.CreateWorkspacePermission(ctx, userID, workspaceID, permissionType)
 		if err != nil {
-			return nil, "", nil, err
+			return nil, nil, fmt.Errorf("airbyte-connector: failed to create permission %s for user %s in workspace %s: %w", permissionType, userID, workspaceID, err)
 		}
 
-		rv = append(rv, grant.NewGrant(resource, permissionType, userResource.Id))
+	case strings.ToLower(existingPermission.PermissionType) == permissionType:
+		l.Debug(
+			"airbyte-connector: user already has the workspace role",
+			zap.String("user_id", userID),
+			zap.String("workspace_id", workspaceID),
+			zap.String("permission_type", permissionType),
+		)
+		annos.Update(&v2.GrantAlreadyExists{})
+
+	default:
+		_, err = o.client.UpdatePermission(ctx, existingPermission.PermissionID, permissionType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("airbyte-connector: failed to update permission %s for user %s in workspace %s: %w", existingPermission.PermissionID, userID, workspaceID, err)
+		}
 	}
 
-	return rv, "", nil, nil
+	return []*v2.Grant{grant.NewGrant(entitlement.Resource, permissionType, principal.Id)}, annos, nil
 }
 
-func newWorkspaceBuilder(client *airbyte.Client) *workspaceBuilder {
+// Revoke removes the workspace-scoped permission backing the grant.
+// If the user no longer holds the granted role in the workspace, the grant is reported as already revoked.
+func (o *workspaceBuilder) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	principal := g.Principal
+	entitlement := g.Entitlement
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"airbyte-connector: only users can have workspace roles revoked",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("airbyte-connector: only users can have workspace roles revoked")
+	}
+
+	permissionType := entitlement.Slug
+	workspaceID := entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	existingPermission, err := o.getWorkspacePermission(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var annos annotations.Annotations
+	if existingPermission == nil || strings.ToLower(existingPermission.PermissionType) != permissionType {
+		annos.Update(&v2.GrantAlreadyRevoked{})
+		return annos, nil
+	}
+
+	err = o.client.DeletePermission(ctx, existingPermission.PermissionID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			annos.Update(&v2.GrantAlreadyRevoked{})
+			return annos, nil
+		}
+
+		return nil, fmt.Errorf("airbyte-connector: failed to delete permission %s for user %s in workspace %s: %w", existingPermission.PermissionID, userID, workspaceID, err)
+	}
+
+	return nil, nil
+}
+
+func newWorkspaceBuilder(
+	client *airbyte.Client,
+	index *workspaceIndex,
+	scope *syncScope,
+	capabilities *capabilities,
+	topLevelUnattributedWorkspaces bool,
+	skipTombstonedWorkspaces bool,
+) *workspaceBuilder {
 	return &workspaceBuilder{
-		resourceType: workspaceResourceType,
-		client:       client,
+		resourceType:                   workspaceResourceType,
+		client:                         client,
+		index:                          index,
+		scope:                          scope,
+		capabilities:                   capabilities,
+		topLevelUnattributedWorkspaces: topLevelUnattributedWorkspaces,
+		skipTombstonedWorkspaces:       skipTombstonedWorkspaces,
 	}
 }
 
@@ -243,10 +474,10 @@ func newWorkspaceBuilder(client *airbyte.Client) *workspaceBuilder {
 // by iterating through each organization and fetching its workspaces. This is necessary because the public
 // workspace API endpoint doesn't provide organization information, but we need this relationship for proper
 // resource hierarchy mapping.
-func (o *workspaceBuilder) getAllWorkspacesWithParentOrganizationID(ctx context.Context) ([]*airbyte.Workspace, error) {
+func getAllWorkspacesWithParentOrganizationID(ctx context.Context, client *airbyte.Client) ([]*airbyte.Workspace, error) {
 	allWorkspacesWithParentOrganizationID := make([]*airbyte.Workspace, 0)
 
-	orgs, err := o.client.ListOrganizations(ctx)
+	orgs, err := client.ListOrganizations(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("airbyte-connector: failed to list organizations: %w", err)
 	}
@@ -254,16 +485,30 @@ func (o *workspaceBuilder) getAllWorkspacesWithParentOrganizationID(ctx context.
 	for _, org := range orgs {
 		var rowOffset uint64 = 0
 		for {
-			listWorkspaceReadResponse, nextRowOffset, err := o.client.ListWorkspacesByOrganization(ctx, org.ID, ResourcesPageSize, rowOffset)
+			listWorkspaceReadResponse, nextRowOffset, err := client.ListWorkspacesByOrganization(ctx, org.ID, ResourcesPageSize, rowOffset)
 			if err != nil {
+				// The application may see an organization without being allowed to list its workspaces.
+				// Those workspaces are left without a parent organization instead of failing the sync.
+				if status.Code(err) == codes.PermissionDenied {
+					ctxzap.Extract(ctx).Warn(
+						"airbyte-connector: no access to the workspaces of organization",
+						zap.String("organization_id", org.ID),
+						zap.Error(err),
+					)
+					break
+				}
 				return nil, fmt.Errorf("airbyte-connector: failed to list workspaces: %w", err)
 			}
 
 			for _, workspaceReadResponse := range listWorkspaceReadResponse {
 				workspace := &airbyte.Workspace{
-					ID:             workspaceReadResponse.WorkspaceId,
-					Name:           workspaceReadResponse.Name,
-					OrganizationId: workspaceReadResponse.OrganizationId,
+					ID:                 workspaceReadResponse.WorkspaceId,
+					Name:               workspaceReadResponse.Name,
+					OrganizationId:     workspaceReadResponse.OrganizationId,
+					Slug:               workspaceReadResponse.Slug,
+					DefaultGeography:   workspaceReadResponse.DefaultGeography,
+					Tombstone:          workspaceReadResponse.Tombstone,
+					WebhookConfigCount: len(workspaceReadResponse.WebhookConfigs),
 				}
 				allWorkspacesWithParentOrganizationID = append(allWorkspacesWithParentOrganizationID, workspace)
 			}
@@ -278,3 +523,217 @@ func (o *workspaceBuilder) getAllWorkspacesWithParentOrganizationID(ctx context.
 
 	return allWorkspacesWithParentOrganizationID, nil
 }
+
+// organizationToWorkspacePermissions maps the organization roles to the workspace role they grant on every workspace
+// of the organization. organization_member doesn't grant access to workspaces.
+var organizationToWorkspacePermissions = map[string]string{
+	OrganizationAdmin:  WorkspaceAdmin,
+	OrganizationEditor: WorkspaceEditor,
+	OrganizationRunner: WorkspaceRunner,
+	OrganizationReader: WorkspaceReader,
+}
+
+// workspacePermissionRanks orders the workspace roles, each role includes the access of the lower ones.
+var workspacePermissionRanks = map[string]int{
+	WorkspaceReader: 1,
+	WorkspaceRunner: 2,
+	WorkspaceEditor: 3,
+	WorkspaceAdmin:  4,
+}
+
+// effectiveWorkspacePermission is the workspace role a user effectively holds, out of the role granted on the
+// workspace and the role inherited from the organization.
+type effectiveWorkspacePermission struct {
+	// explicit is the role granted on the workspace, if any.
+	explicit string
+	// inherited is the workspace role granted by the organization role, if any.
+	inherited string
+	// organizationPermissionType is the organization role the inherited role comes from.
+	organizationPermissionType string
+	// effective is the highest of the explicit and the inherited role.
+	effective string
+}
+
+// resolveWorkspacePermission returns the effective workspace role for a workspace role and an organization role,
+// either of which may be empty. The explicit role wins ties.
+func resolveWorkspacePermission(workspacePermissionType, organizationPermissionType string) effectiveWorkspacePermission {
+	p := effectiveWorkspacePermission{
+		organizationPermissionType: organizationPermissionType,
+		inherited:                  organizationToWorkspacePermissions[organizationPermissionType],
+	}
+	if _, ok := workspacePermissionRanks[workspacePermissionType]; ok {
+		p.explicit = workspacePermissionType
+	}
+
+	p.effective = p.explicit
+	if workspacePermissionRanks[p.inherited] > workspacePermissionRanks[p.explicit] {
+		p.effective = p.inherited
+	}
+
+	return p
+}
+
+// source returns where the effective role comes from, "workspace" or "organization".
+func (p effectiveWorkspacePermission) source() string {
+	switch {
+	case p.effective == "":
+		return ""
+	case p.effective == p.explicit:
+		return airbyte.PermissionScopeWorkspace
+	default:
+		return airbyte.PermissionScopeOrganization
+	}
+}
+
+func (p effectiveWorkspacePermission) metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"effective_permission":    p.effective,
+		"effective_source":        p.source(),
+		"workspace_permission":    p.explicit,
+		"organization_permission": p.organizationPermissionType,
+	}
+}
+
+// workspaceOrganizationID returns the ID of the synced organization the workspace belongs to, or an empty string if
+// the organization isn't synced.
+func (o *workspaceBuilder) workspaceOrganizationID(ctx context.Context, resource *v2.Resource) (string, error) {
+	parent := resource.GetParentResourceId()
+	if parent != nil && parent.ResourceType == organizationResourceType.Id {
+		if parent.Resource == unattributedOrganizationID {
+			return "", nil
+		}
+		return parent.Resource, nil
+	}
+
+	// Unattributed workspaces listed at the top level have no parent, the index tells whether that is still the case.
+	organizationIDs, err := o.index.snapshot(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return organizationIDs[resource.Id.Resource], nil
+}
+
+// organizationEntitlementID returns the ID of the entitlement of an organization role.
+func organizationEntitlementID(organizationID, permissionType string) string {
+	return ent.NewEntitlementID(&v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: organizationResourceType.Id,
+			Resource:     organizationID,
+		},
+	}, permissionType)
+}
+
+// organizationWorkspaceGrants returns the grants of the workspace roles to the organization of the workspace. They are
+// expanded to every user holding the matching organization role.
+func organizationWorkspaceGrants(resource *v2.Resource, organizationID string) []*v2.Grant {
+	organizationResourceID := &v2.ResourceId{
+		ResourceType: organizationResourceType.Id,
+		Resource:     organizationID,
+	}
+
+	grants := make([]*v2.Grant, 0, len(organizationToWorkspacePermissions))
+	for _, organizationPermissionType := range PublicOrganizationPermissionsTypes {
+		workspacePermissionType, ok := organizationToWorkspacePermissions[organizationPermissionType]
+		if !ok {
+			continue
+		}
+
+		entitlementID := organizationEntitlementID(organizationID, organizationPermissionType)
+		grants = append(grants, grant.NewGrant(
+			resource,
+			workspacePermissionType,
+			organizationResourceID,
+			grant.WithAnnotation(
+				&v2.GrantExpandable{
+					EntitlementIds:  []string{entitlementID},
+					Shallow:         true,
+					ResourceTypeIds: []string{userResourceType.Id},
+				},
+				&v2.GrantImmutable{
+					SourceId: entitlementID,
+				},
+			),
+		))
+	}
+
+	return grants
+}
+
+// inheritedWorkspaceGrant returns the workspace grant a user inherits from a role in an organization whose workspaces
+// aren't parented to it, or nil if the role doesn't grant access to workspaces.
+// When the organization is synced, the grant is immutable with the organization role as source. Otherwise the role
+// has no entitlement to reference, so a plain grant is returned.
+func inheritedWorkspaceGrant(
+	resource *v2.Resource,
+	principalID *v2.ResourceId,
+	organizationPermission *airbyte.PermissionRead,
+	sourced bool,
+	opts ...grant.GrantOption,
+) *v2.Grant {
+	organizationPermissionType := strings.ToLower(organizationPermission.PermissionType)
+
+	workspacePermissionType, ok := organizationToWorkspacePermissions[organizationPermissionType]
+	if !ok {
+		return nil
+	}
+
+	if !sourced {
+		return grant.NewGrant(resource, workspacePermissionType, principalID, opts...)
+	}
+
+	entitlementID := organizationEntitlementID(organizationPermission.OrganizationID, organizationPermissionType)
+	opts = append(opts, grant.WithAnnotation(&v2.GrantImmutable{
+		SourceId: entitlementID,
+	}))
+	g := grant.NewGrant(resource, workspacePermissionType, principalID, opts...)
+	g.Sources = &v2.GrantSources{
+		Sources: map[string]*v2.GrantSources_GrantSource{
+			entitlementID: {},
+		},
+	}
+
+	return g
+}
+
+// listUnattributedWorkspaces returns the workspaces missing from the workspace to organization mapping, because they
+// belong to organizations the application can't access.
+func listUnattributedWorkspaces(ctx context.Context, client *airbyte.Client, organizationIDs map[string]string) ([]*airbyte.WorkspaceResponse, error) {
+	var unattributed []*airbyte.WorkspaceResponse
+	offset := ""
+	for {
+		workspaces, nextOffset, err := client.ListAllWorkspaces(ctx, ResourcesPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("airbyte-connector: failed to list workspaces: %w", err)
+		}
+
+		for _, workspace := range workspaces {
+			if organizationIDs[workspace.ID] == "" {
+				unattributed = append(unattributed, workspace)
+			}
+		}
+
+		if nextOffset == "" {
+			return unattributed, nil
+		}
+
+		offset = nextOffset
+	}
+}
+
+// getWorkspacePermission returns the workspace-scoped permission the user holds in the workspace, or nil if the
+// user has no direct workspace permission. Permissions inherited from the organization are ignored.
+func (o *workspaceBuilder) getWorkspacePermission(ctx context.Context, userID, workspaceID string) (*airbyte.PermissionRead, error) {
+	usersWithAccessInfo, err := o.client.ListUsersWithAccessInfoByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("airbyte-connector: failed to list users under workspace %s: %w", workspaceID, err)
+	}
+
+	for _, userWithAccessInfo := range usersWithAccessInfo {
+		if userWithAccessInfo.UserID == userID {
+			return userWithAccessInfo.WorkspacePermission, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,103 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/conductorone/baton-airbyte/pkg/airbyte"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestResolveWorkspacePermission(t *testing.T) {
+	testCases := []struct {
+		message                    string
+		workspacePermissionType    string
+		organizationPermissionType string
+		effective                  string
+		source                     string
+	}{
+		{"organization role above workspace role", WorkspaceReader, OrganizationAdmin, WorkspaceAdmin, "organization"},
+		{"workspace role above organization role", WorkspaceEditor, OrganizationRunner, WorkspaceEditor, "workspace"},
+		{"explicit role wins ties", WorkspaceRunner, OrganizationRunner, WorkspaceRunner, "workspace"},
+		{"workspace role only", WorkspaceAdmin, "", WorkspaceAdmin, "workspace"},
+		{"organization role only", "", OrganizationReader, WorkspaceReader, "organization"},
+		{"organization member has no workspace access", "", OrganizationMember, "", ""},
+		{"no role", "", "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			p := resolveWorkspacePermission(tc.workspacePermissionType, tc.organizationPermissionType)
+			if p.effective != tc.effective {
+				t.Fatalf("expected effective permission %q, got %q", tc.effective, p.effective)
+			}
+			if p.source() != tc.source {
+				t.Fatalf("expected source %q, got %q", tc.source, p.source())
+			}
+		})
+	}
+}
+
+func TestWorkspaceResource(t *testing.T) {
+	workspace := &airbyte.WorkspaceResponse{ID: "ws-1", Name: "Production", DataResidency: "eu"}
+	workspace.Notifications.Failure.Email.Enabled = true
+	workspace.Notifications.Failure.Webhook.Enabled = true
+	details := &airbyte.Workspace{ID: "ws-1", OrganizationId: "org-1", Slug: "production", Tombstone: true}
+
+	resource, err := workspaceResource(workspace, details, "https://airbyte.example.com/workspaces/ws-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appTrait, err := rs.GetAppTrait(resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]string{
+		"data_residency":        "eu",
+		"slug":                  "production",
+		"notifications_failure": "email,webhook",
+		"notifications_success": "",
+	} {
+		if value, _ := rs.GetProfileStringValue(appTrait.Profile, key); value != expected {
+			t.Fatalf("expected %s %q, got %q", key, expected, value)
+		}
+	}
+	if !appTrait.Profile.GetFields()["tombstone"].GetBoolValue() {
+		t.Fatal("expected the workspace to be flagged as tombstoned")
+	}
+
+	externalLink := &v2.ExternalLink{}
+	annos := annotations.Annotations(resource.Annotations)
+	if ok, err := annos.Pick(externalLink); err != nil || !ok {
+		t.Fatalf("expected an external link, got %v", err)
+	}
+	if externalLink.Url != "https://airbyte.example.com/workspaces/ws-1" {
+		t.Fatalf("unexpected external link %q", externalLink.Url)
+	}
+}
+
+func TestInheritedWorkspaceGrant(t *testing.T) {
+	workspace := &v2.Resource{Id: &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "ws-1"}}
+	principalID := &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "user-1"}
+	permission := &airbyte.PermissionRead{OrganizationID: "org-1", PermissionType: "ORGANIZATION_EDITOR"}
+
+	sourced := inheritedWorkspaceGrant(workspace, principalID, permission, true)
+	if _, ok := sourced.GetSources().GetSources()[organizationEntitlementID("org-1", OrganizationEditor)]; !ok {
+		t.Fatalf("expected the organization role as source, got %v", sourced.GetSources())
+	}
+	sourcedAnnos := annotations.Annotations(sourced.Annotations)
+	if !sourcedAnnos.Contains(&v2.GrantImmutable{}) {
+		t.Fatal("expected the grant to be immutable")
+	}
+
+	plain := inheritedWorkspaceGrant(workspace, principalID, permission, false)
+	if entitlementID := ent.NewEntitlementID(workspace, WorkspaceEditor); plain.GetEntitlement().GetId() != entitlementID {
+		t.Fatalf("expected the %s entitlement, got %s", entitlementID, plain.GetEntitlement().GetId())
+	}
+	if plain.GetSources() != nil || len(plain.Annotations) != 0 {
+		t.Fatal("expected a plain grant for an organization that isn't synced")
+	}
+}